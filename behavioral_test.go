@@ -170,13 +170,15 @@ func TestBehavioral_ConcurrentResolutions(t *testing.T) {
 	}
 }
 
+var errTest = errors.New("test error")
+
 // TestBehavioral_ErrorHandling tests current error handling patterns
 func TestBehavioral_ErrorHandling(t *testing.T) {
 	scope := NewScope()
 
 	// Executor that returns error
 	errorExec := Provide(func(ctx *ResolveCtx) (int, error) {
-		return 0, errors.New("test error")
+		return 0, errTest
 	})
 
 	// Dependent executor
@@ -196,7 +198,7 @@ func TestBehavioral_ErrorHandling(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error from errorExec")
 	}
-	if err.Error() != "test error" {
+	if !errors.Is(err, errTest) {
 		t.Errorf("Expected 'test error', got %v", err)
 	}
 
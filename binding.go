@@ -0,0 +1,92 @@
+package pumped
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// As returns an executor that exposes exec as the interface I. Both resolve
+// to the same instance: the interface executor reactively depends on exec
+// and converts its cached value. As panics if T does not implement I.
+func As[I any, T any](exec *Executor[T], opts ...ExecutorOption) *Executor[I] {
+	iface := reflect.TypeFor[I]()
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("pumped: As target %v is not an interface", iface))
+	}
+	if concrete := reflect.TypeFor[T](); !concrete.Implements(iface) {
+		panic(fmt.Sprintf("pumped: %v does not implement %v", concrete, iface))
+	}
+
+	opts = append([]ExecutorOption{WithLifetime(LifetimeOf(exec))}, opts...)
+	return Derive1(exec.Reactive(), func(ctx *ResolveCtx, c *Controller[T]) (I, error) {
+		val, err := c.Get()
+		if err != nil {
+			var zero I
+			return zero, err
+		}
+		// A nil interface value converts to the zero I
+		iv, _ := any(val).(I)
+		return iv, nil
+	}, opts...)
+}
+
+type decorator func(*ResolveCtx, any) (any, error)
+
+// Decorate returns an option that wraps every value the scope resolves for
+// exec, so all consumers receive the decorated value. Decorators run in
+// registration order, each receiving the previous result; decorators of a
+// parent scope run before those of an overlay.
+func Decorate[T any](exec *Executor[T], fn func(*ResolveCtx, T) (T, error)) ScopeOption {
+	return func(s *Scope) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.decorators == nil {
+			s.decorators = make(map[AnyExecutor][]decorator)
+		}
+		s.decorators[exec] = append(s.decorators[exec], func(ctx *ResolveCtx, val any) (any, error) {
+			typed, err := SafeTypeAssertion[T](val)
+			if err != nil {
+				return nil, err
+			}
+			return fn(ctx, typed)
+		})
+	}
+}
+
+// lookupDecorators collects decorators for exec from the root scope down
+func (s *Scope) lookupDecorators(exec AnyExecutor) []decorator {
+	var decs []decorator
+	if s.parent != nil {
+		decs = s.parent.lookupDecorators(exec)
+	}
+	s.mu.RLock()
+	decs = append(decs, s.decorators[exec]...)
+	s.mu.RUnlock()
+	return decs
+}
+
+// decorate applies the decorators registered for exec. Without ctx the
+// decorators' cleanups are owned by the scope.
+func (s *Scope) decorate(exec AnyExecutor, val any, ctx *ResolveCtx) (any, error) {
+	decs := s.lookupDecorators(exec)
+	if len(decs) == 0 {
+		return val, nil
+	}
+	if ctx == nil {
+		ctx = &ResolveCtx{
+			scope:      s,
+			executorID: exec,
+			registered: true,
+		}
+	}
+
+	for _, dec := range decs {
+		var err error
+		val, err = dec(ctx, val)
+		if err != nil {
+			return nil, s.resolveError(exec, err, "decorator")
+		}
+	}
+	return val, nil
+}
@@ -0,0 +1,174 @@
+package pumped
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type englishGreeter struct {
+	name string
+}
+
+func (g *englishGreeter) Greet() string { return "hello " + g.name }
+
+type loudGreeter struct {
+	inner greeter
+}
+
+func (g loudGreeter) Greet() string { return g.inner.Greet() + "!" }
+
+func TestAsSharesInstance(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	var builds atomic.Int32
+	concrete := Provide(func(ctx *ResolveCtx) (*englishGreeter, error) {
+		builds.Add(1)
+		return &englishGreeter{name: "ann"}, nil
+	})
+	iface := As[greeter](concrete)
+
+	g, err := Resolve(scope, iface)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	c, _ := Resolve(scope, concrete)
+
+	if g != greeter(c) {
+		t.Error("expected interface and concrete executors to share the instance")
+	}
+	if builds.Load() != 1 {
+		t.Errorf("expected one build, got %d", builds.Load())
+	}
+}
+
+func TestAsRejectsNonImplementingType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for a type that does not implement the interface")
+		}
+	}()
+	As[greeter](Provide(func(ctx *ResolveCtx) (int, error) {
+		return 0, nil
+	}))
+}
+
+func TestDecorateChainsInRegistrationOrder(t *testing.T) {
+	base := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "svc", nil
+	})
+	consumer := Derive1(base, func(ctx *ResolveCtx, b *Controller[string]) (string, error) {
+		return b.Get()
+	})
+
+	var cleaned atomic.Bool
+	scope := NewScope(
+		Decorate(base, func(ctx *ResolveCtx, v string) (string, error) {
+			ctx.OnCleanup(func() error {
+				cleaned.Store(true)
+				return nil
+			})
+			return "cache(" + v + ")", nil
+		}),
+		Decorate(base, func(ctx *ResolveCtx, v string) (string, error) {
+			return "log(" + v + ")", nil
+		}),
+	)
+
+	got, err := Resolve(scope, consumer)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if got != "log(cache(svc))" {
+		t.Errorf("expected decorators applied in registration order, got %q", got)
+	}
+
+	if err := scope.Dispose(); err != nil {
+		t.Fatalf("dispose failed: %v", err)
+	}
+	if !cleaned.Load() {
+		t.Error("expected decorator cleanup on dispose")
+	}
+}
+
+func TestDecorateInterfaceAndPreset(t *testing.T) {
+	concrete := Provide(func(ctx *ResolveCtx) (*englishGreeter, error) {
+		return &englishGreeter{name: "ann"}, nil
+	})
+	iface := As[greeter](concrete)
+
+	scope := NewScope(
+		WithPreset(concrete, &englishGreeter{name: "bob"}),
+		Decorate(iface, func(ctx *ResolveCtx, g greeter) (greeter, error) {
+			return loudGreeter{inner: g}, nil
+		}),
+	)
+	defer scope.Dispose()
+
+	g, err := Resolve(scope, iface)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if g.Greet() != "hello bob!" {
+		t.Errorf("expected decorated preset, got %q", g.Greet())
+	}
+}
+
+func TestDecoratorErrorFailsResolution(t *testing.T) {
+	base := Provide(func(ctx *ResolveCtx) (int, error) {
+		return 1, nil
+	})
+	boom := errors.New("boom")
+	scope := NewScope(Decorate(base, func(ctx *ResolveCtx, v int) (int, error) {
+		return 0, boom
+	}))
+	defer scope.Dispose()
+
+	if _, err := Resolve(scope, base); !errors.Is(err, boom) {
+		t.Errorf("expected decorator error, got %v", err)
+	}
+	if Accessor(scope, base).IsCached() {
+		t.Error("failed decoration must not be cached")
+	}
+}
+
+func TestUpdateDecoratesValue(t *testing.T) {
+	base := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "svc", nil
+	})
+	consumer := Derive1(base.Reactive(), func(ctx *ResolveCtx, b *Controller[string]) (string, error) {
+		return b.Get()
+	})
+
+	rejected := errors.New("rejected")
+	scope := NewScope(Decorate(base, func(ctx *ResolveCtx, v string) (string, error) {
+		if v == "bad" {
+			return "", rejected
+		}
+		return "log(" + v + ")", nil
+	}))
+	defer scope.Dispose()
+
+	if _, err := Resolve(scope, consumer); err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if err := Accessor(scope, base).Update(context.Background(), "next"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if got, _ := Resolve(scope, consumer); got != "log(next)" {
+		t.Errorf("expected the dependent to see the decorated update, got %q", got)
+	}
+
+	if err := Update(context.Background(), scope, base, "bad"); !errors.Is(err, rejected) {
+		t.Fatalf("expected the decorator error, got %v", err)
+	}
+	if got, _ := Resolve(scope, base); got != "log(next)" {
+		t.Errorf("expected a failed update to keep the current value, got %q", got)
+	}
+}
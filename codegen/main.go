@@ -32,8 +32,9 @@ func generateDerive(n int) string {
 	controllers := []string{}
 	for i := 1; i <= n; i++ {
 		controllers = append(controllers, fmt.Sprintf(`ctrl%d := &Controller[D%d]{
-				executor: d%d.GetExecutor().(*Executor[D%d]),
-				scope:    ctx.scope,
+				executor:  d%d.GetExecutor().(*Executor[D%d]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}`, i, i, i, i))
 	}
 
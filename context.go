@@ -13,11 +13,22 @@ type ResolveCtx struct {
 	cleanups   []cleanupEntry
 	cleanupMu  sync.Mutex
 	executorID AnyExecutor
+	execCtx    *ExecutionCtx
+	// registered is set once cleanups were handed to their owner; later
+	// ones, e.g. from a retained controller resolving a transient
+	// dependency, go straight to the scope
+	registered bool
 }
 
 // OnCleanup registers a cleanup function to be called when the executor is disposed
-// Extensions can read tags from the executor to determine cleanup behavior
+// Extensions can read tags from the executor to determine cleanup behavior.
+// Inside a flow the cleanup is owned by the execution and runs when the flow ends.
 func (ctx *ResolveCtx) OnCleanup(fn func() error) {
+	if ctx.execCtx != nil {
+		ctx.execCtx.OnCleanup(fn)
+		return
+	}
+
 	ctx.cleanupMu.Lock()
 	defer ctx.cleanupMu.Unlock()
 
@@ -25,9 +36,23 @@ func (ctx *ResolveCtx) OnCleanup(fn func() error) {
 		fn:    fn,
 		order: len(ctx.cleanups),
 	}
+	if ctx.registered {
+		ctx.scope.appendCleanups(ctx.executorID, []cleanupEntry{entry})
+		return
+	}
 	ctx.cleanups = append(ctx.cleanups, entry)
 }
 
+// takeCleanups returns the cleanups registered so far and routes later ones
+// to the scope
+func (ctx *ResolveCtx) takeCleanups() []cleanupEntry {
+	ctx.cleanupMu.Lock()
+	defer ctx.cleanupMu.Unlock()
+
+	ctx.registered = true
+	return ctx.cleanups
+}
+
 // GetTag retrieves a tag value from the scope
 func (ctx *ResolveCtx) GetTag(tag any) (any, bool) {
 	return ctx.scope.GetTag(tag)
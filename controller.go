@@ -6,16 +6,39 @@ import "context"
 type Controller[T any] struct {
 	executor *Executor[T]
 	scope    *Scope
+	// requester is the executor or execution the controller was handed to;
+	// it owns transient values and scopes per-execution values
+	requester cleanupOwner
 }
 
 // Get retrieves the latest value (resolves if not cached)
 func (c *Controller[T]) Get() (T, error) {
-	return Resolve(c.scope, c.executor)
+	return resolveFor(c.scope, c.executor, c.requester)
+}
+
+// TryGet resolves the value, reporting absence instead of an error. Use it
+// with Optional dependencies: a failed singleton is reported absent without
+// running its factory again until it is updated or released.
+func (c *Controller[T]) TryGet() (T, bool) {
+	var zero T
+	singleton := LifetimeOf(c.executor) == Singleton
+	if singleton && c.scope.optionalFailure(c.executor) != nil {
+		return zero, false
+	}
+
+	val, err := c.Get()
+	if err != nil {
+		if singleton {
+			c.scope.rememberFailure(c.executor, err)
+		}
+		return zero, false
+	}
+	return val, true
 }
 
 // Peek retrieves the cached value without resolving
 func (c *Controller[T]) Peek() (T, bool) {
-	val, ok := c.scope.cache.Load(c.executor)
+	val, ok := c.scope.owner(c.executor).cache.Load(c.executor)
 	if !ok {
 		var zero T
 		return zero, false
@@ -42,7 +65,10 @@ func (c *Controller[T]) Set(ctx context.Context, newVal T) error {
 
 // Release invalidates the cached value
 func (c *Controller[T]) Release() error {
-	c.scope.cache.Delete(c.executor)
+	owner := c.scope.owner(c.executor)
+	owner.cache.Delete(c.executor)
+	owner.failures.Delete(c.executor)
+	owner.publishExecutor(EventExecutorInvalidated, c.executor)
 	return nil
 }
 
@@ -57,6 +83,6 @@ func (c *Controller[T]) Reload() (T, error) {
 
 // IsCached checks if the value is currently cached
 func (c *Controller[T]) IsCached() bool {
-	_, ok := c.scope.cache.Load(c.executor)
+	_, ok := c.scope.owner(c.executor).cache.Load(c.executor)
 	return ok
 }
@@ -0,0 +1,257 @@
+package pumped
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes activation times for scheduled flows
+type Schedule interface {
+	// Next returns the first activation strictly after t, or the zero time
+	// when there is none
+	Next(t time.Time) time.Time
+}
+
+type intervalSchedule struct {
+	every time.Duration
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.every)
+}
+
+// Every returns a schedule that activates at a fixed interval. It panics if
+// d is not positive.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		panic(fmt.Sprintf("pumped: Every requires a positive interval, got %v", d))
+	}
+	return intervalSchedule{every: d}
+}
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard five-field cron expression
+// (minute hour day-of-month month day-of-week). Fields accept *, values,
+// ranges (1-5), steps (*/15, 0-30/10), lists (1,15) and month and weekday
+// names. When both day fields are restricted, a day matching either one
+// activates, as in Vixie cron. The descriptors @yearly, @monthly, @weekly,
+// @daily, @hourly and "@every <duration>" are also accepted. Times are
+// evaluated in the location of the time passed to Next.
+func ParseCron(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expr, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("cron %q: interval must be positive", expr)
+		}
+		return Every(d), nil
+	}
+	if std, ok := cronDescriptors[expr]; ok {
+		expr = std
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	sched := &cronSchedule{
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+	}
+
+	var err error
+	specs := []struct {
+		dst   *uint64
+		field cronField
+		text  string
+	}{
+		{&sched.minute, cronMinute, fields[0]},
+		{&sched.hour, cronHour, fields[1]},
+		{&sched.dom, cronDom, fields[2]},
+		{&sched.month, cronMonth, fields[3]},
+		{&sched.dow, cronDow, fields[4]},
+	}
+	for _, spec := range specs {
+		if *spec.dst, err = spec.field.parse(spec.text); err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expr, err)
+		}
+	}
+
+	// Sunday may be written as 7
+	if sched.dow&(1<<7) != 0 {
+		sched.dow |= 1
+	}
+
+	return sched, nil
+}
+
+// MustParseCron is like ParseCron but panics on an invalid expression
+func MustParseCron(expr string) Schedule {
+	sched, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return sched
+}
+
+func (f cronField) parse(text string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(text, ",") {
+		b, err := f.parsePart(part)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+func (f cronField) parsePart(part string) (uint64, error) {
+	rangeText, stepText, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepText)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid %s step %q", f.name, stepText)
+		}
+		step = n
+	}
+
+	var lo, hi int
+	switch {
+	case rangeText == "*" || rangeText == "?":
+		lo, hi = f.min, f.max
+	case strings.Contains(rangeText, "-"):
+		loText, hiText, _ := strings.Cut(rangeText, "-")
+		var err error
+		if lo, err = f.value(loText); err != nil {
+			return 0, err
+		}
+		if hi, err = f.value(hiText); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("invalid %s range %q", f.name, rangeText)
+		}
+	default:
+		var err error
+		if lo, err = f.value(rangeText); err != nil {
+			return 0, err
+		}
+		hi = lo
+		if hasStep {
+			hi = f.max
+		}
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func (f cronField) value(text string) (int, error) {
+	if v, ok := f.names[strings.ToLower(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q", f.name, text)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s value %d out of range [%d, %d]", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.Year() + 5
+
+wrap:
+	if t.Year() > limit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	return t
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,68 @@
+package pumped
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronNext(t *testing.T) {
+	base := time.Date(2024, time.January, 15, 10, 30, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", base, base.Add(time.Minute)},
+		{"*/15 * * * *", base, time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 * * * *", base, time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"30 9 * * *", base, time.Date(2024, 1, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", base, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * sat", base, time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", base, time.Date(2024, 1, 21, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", base, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 8-10/2 * * mon-fri", base, time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC)},
+		{"0 0 1 * sun", base, time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"5,35 * * * *", base, time.Date(2024, 1, 15, 10, 35, 0, 0, time.UTC)},
+		{"@daily", base, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", base, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", base, base.Add(90 * time.Second)},
+		{"* * * * *", base.Add(20 * time.Second), base.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		sched, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.expr, err)
+		}
+		if got := sched.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every -1s",
+		"@every soon",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestCronNeverMatching(t *testing.T) {
+	sched := MustParseCron("0 0 31 feb *")
+	if next := sched.Next(time.Now()); !next.IsZero() {
+		t.Errorf("expected no activation, got %v", next)
+	}
+}
@@ -31,6 +31,12 @@
 //	serverCtrl := pumped.Accessor(scope, server)
 //	srv, err := serverCtrl.Get()
 //
+// Failed resolutions return a *ResolveError naming the executor that failed
+// and the Path from the requested executor down to it. The original error
+// stays reachable through errors.Is and errors.As. Resolution failures
+// carry a stack trace only in scopes created WithStackTraces, or when the
+// factory panicked.
+//
 // # Dependency Modes
 //
 // Dependencies can be resolved in different modes:
@@ -67,6 +73,96 @@
 //	    },
 //	)
 //
+//	// Optional: a failure does not fail the dependent
+//	service := pumped.Derive1(
+//	    metrics.Optional(),
+//	    func(ctx *pumped.ResolveCtx, m *pumped.Controller[*Metrics]) (*Service, error) {
+//	        sink, ok := m.TryGet()  // ok is false if metrics failed
+//	    },
+//	)
+//
+// OrElse resolves a fallback when the primary fails, and Scope.FallbackBranch
+// reports which branch was taken:
+//
+//	cache := redisCache.OrElse(memoryCache)
+//
+// A failed Optional singleton is not retried until it is updated or
+// released; updating the primary of an OrElse switches back to it.
+//
+// # Lifetimes
+//
+// Executors are singletons by default. WithLifetime declares shorter-lived
+// values:
+//
+//	// Transient: a fresh value on every Resolve or Controller.Get
+//	buffer := pumped.Provide(func(ctx *pumped.ResolveCtx) (*bytes.Buffer, error) {
+//	    return new(bytes.Buffer), nil
+//	}, pumped.WithLifetime(pumped.Transient))
+//
+//	// Scoped: one value per scope, and one per execution inside flows
+//	validator := pumped.Derive1(schema, newValidator,
+//	    pumped.WithLifetime(pumped.Scoped))
+//
+// Cleanups of transient values belong to the requester: the dependent
+// executor or the flow execution. Resolving a transient that registers
+// cleanups at the top level runs them and fails with ErrTransientCleanup.
+// A singleton that depends on a scoped or transient executor fails to
+// resolve with a LifetimeError.
+//
+// # Families
+//
+// A family builds one executor per key from a shared factory:
+//
+//	pools := pumped.Family1(config.Reactive(),
+//	    func(ctx *pumped.ResolveCtx, shard int, cfg *pumped.Controller[*Config]) (*Pool, error) {
+//	        c, _ := cfg.Get()
+//	        return OpenPool(c.ShardDSN(shard))
+//	    },
+//	    pumped.WithFamilyName("pools"),
+//	)
+//
+//	pool, err := pumped.Resolve(scope, pools.Get(3))
+//
+// Members are ordinary executors tagged with FamilyMember, so caching,
+// cleanups and reactive invalidation work per key. EvictIdle releases
+// members that have not been requested or resolved recently.
+//
+// # Groups
+//
+// Groups collect executors contributed by many packages:
+//
+//	var Checks = pumped.NewGroup[HealthCheck]("health")
+//
+//	var dbCheck = pumped.Derive1(db, newDBCheck,
+//	    pumped.JoinGroup(Checks), pumped.WithGroupPriority(1))
+//
+//	var health = pumped.DeriveGroup(Checks,
+//	    func(ctx *pumped.ResolveCtx, checks []HealthCheck) (*Health, error) {
+//	        return NewHealth(checks), nil
+//	    })
+//
+// WithGroupMember adds members to a single scope, and presets on members or
+// on Group.All replace them in tests.
+//
+// # Interfaces and Decorators
+//
+// As exposes a concrete executor through an interface, sharing its cached
+// instance:
+//
+//	userService := pumped.Derive1(db, services.NewUserService)
+//	users := pumped.As[UserStore](userService)
+//
+// Decorate wraps an executor's value in one scope, so every consumer sees
+// the decorated value without editing the executor:
+//
+//	scope := pumped.NewScope(
+//	    pumped.Decorate(users, func(ctx *pumped.ResolveCtx, s UserStore) (UserStore, error) {
+//	        return NewCachingStore(s), nil
+//	    }),
+//	)
+//
+// Decorators run in registration order.
+//
 // # Controllers
 //
 // Controllers provide lifecycle operations for executor values:
@@ -123,6 +219,21 @@
 //	    },
 //	)
 //
+// # Cancellation
+//
+// When the caller's context is cancelled, Exec waits up to the grace period
+// for the factory to return. A factory that returns in time reports its own
+// result, error or panic; otherwise it is abandoned. Abandoned executions are
+// tagged with Abandoned(), and once the factory finally returns its outcome
+// is recorded on the ExecutionNode as LateOutput() or LateError():
+//
+//	scope := pumped.NewScope(pumped.WithCancellationGracePeriod(100 * time.Millisecond))
+//
+//	// In tests, ensure no factory goroutines outlive the test
+//	if stats := scope.FlowStats(); stats.InFlight > 0 {
+//	    t.Errorf("leaked flows: %+v", stats)
+//	}
+//
 // # Execution Context
 //
 // ExecutionCtx provides data isolation and hierarchical lookups:
@@ -142,6 +253,14 @@
 //	// Lookup: try self, then parents, then scope
 //	val, ok := execCtx.Lookup(someTag)
 //
+// Typed helpers return the tag's value type and report missing tags
+// (ErrTagNotFound) or mismatched types (*TagTypeError) as errors:
+//
+//	pumped.SetValue(execCtx, tenantTag, "acme")
+//	tenant, err := pumped.LookupValue(execCtx, tenantTag)
+//
+// ExecutionCtx data is safe to use from goroutines the flow spawns.
+//
 // # Tags
 //
 // Tags provide type-safe metadata for executors, scopes, and flows:
@@ -198,6 +317,39 @@
 //	    }),
 //	)
 //
+// Wrap also receives flow executions as OpExec operations carrying the
+// ExecutionCtx and flow, so extensions can retry, substitute results or run
+// the flow under a modified context. Middleware for a single flow attaches
+// with WithFlowMiddleware:
+//
+//	flow := pumped.Flow1(db, handler,
+//	    pumped.WithFlowMiddleware(func(execCtx *pumped.ExecutionCtx, next func() (any, error)) (any, error) {
+//	        if !authorized(execCtx) {
+//	            return nil, ErrForbidden
+//	        }
+//	        return next()
+//	    }),
+//	)
+//
+// An extension or middleware that refuses to run a flow returns an error
+// matching ErrRejected; the execution is recorded as ExecutionStatusRejected
+// rather than failed.
+//
+// # Events
+//
+// For dashboards and alerting that don't need a full extension, a scope
+// publishes lifecycle events to subscribers:
+//
+//	sub := scope.Events(ctx, pumped.EventFlowNames("checkout"), pumped.EventBuffer(1024))
+//	for ev := range sub.C {
+//	    log.Printf("%d %s %s %v", ev.Seq, ev.Kind, ev.FlowName, ev.Err)
+//	}
+//
+// Events carry a scope-wide sequence number and reach each subscriber in
+// that order. A flow's started event precedes its terminal event, with its
+// sub-flows' events in between. Publishing never blocks; events that don't
+// fit the buffer are dropped and counted by Dropped.
+//
 // # Resource Cleanup
 //
 // Register cleanup functions for automatic resource management:
@@ -213,6 +365,24 @@
 // Cleanup functions are called when:
 //   - Reactive dependents are invalidated (OnUpdate)
 //   - Scope is disposed (scope.Dispose())
+//   - The factory panics after registering them
+//
+// A panicking factory is recovered into a ResolveError with context "panic"
+// and the stack attached; nothing is cached and extensions implementing
+// ResolvePanicHandler are told through OnResolvePanic.
+//
+// Flows own their resources through the execution context. Flow cleanups
+// run when the flow ends, including on failure, panic or cancellation:
+//
+//	txFlow := pumped.Flow1(db,
+//	    func(execCtx *pumped.ExecutionCtx, dbCtrl *pumped.Controller[*DB]) (string, error) {
+//	        tx, _ := pumped.ResolveInExecution(execCtx, txExecutor)  // one per execution
+//	        execCtx.OnCleanup(func() error {
+//	            return tx.Rollback()
+//	        })
+//	        return tx.Commit()
+//	    },
+//	)
 //
 // # Testing with Presets
 //
@@ -237,6 +407,43 @@
 //	    pumped.WithPreset(realDB, mockDBExecutor),  // executor preset
 //	)
 //
+// Presets can also apply to a single execution tree without building a new
+// scope. Overridden values are cached per execution and never leak into the
+// shared scope cache:
+//
+//	result, execCtx, err := pumped.Exec(scope, ctx, fetchUser,
+//	    pumped.WithExecPreset(tenantExecutor, "acme"),
+//	)
+//
+// Existing scopes can change presets at runtime. The cached value and its
+// reactive dependents are invalidated, and extensions see an OpPreset
+// operation:
+//
+//	if err := scope.SetPreset(featureFlag, true); err != nil {
+//	    return err  // e.g. type mismatch
+//	}
+//	defer scope.ClearPreset(featureFlag)
+//
+// Fork gives each subtest an isolated scope that starts from the shared
+// fixtures already resolved in the parent:
+//
+//	base := pumped.NewScope()
+//	pumped.Resolve(base, expensiveFixture)
+//
+//	t.Run("case", func(t *testing.T) {
+//	    scope, err := base.Fork(pumped.ForkCopyFor(state, cloneState))
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//	    defer scope.Dispose()
+//	    pumped.Update(ctx, scope, state, modified)  // invisible to other forks
+//	})
+//
+// The pumpedtest package wraps these patterns: its scopes dispose
+// themselves when the test ends, count factory calls, assert on cached and
+// invalidated executors and execution tree shape, and fail the test on
+// unhandled cleanup errors, cleanups that never ran, or leaked flows.
+//
 // # Execution Tree
 //
 // Query execution history and build observability:
@@ -260,6 +467,78 @@
 //	    return ok && status == pumped.ExecutionStatusFailed
 //	})
 //
+// Query uses indexes on flow name, status and start time and returns
+// detached snapshots:
+//
+//	slowFailures := tree.Query().
+//	    FlowName("checkout").
+//	    Status(pumped.ExecutionStatusFailed).
+//	    MinDuration(time.Second).
+//	    OrderBy(pumped.SortByStartTime, true).
+//	    Limit(20).
+//	    Run()
+//
+// Snapshots export as NDJSON or OTLP-JSON spans. Tags are keyed by name;
+// register codecs for tag values that need a custom representation:
+//
+//	enc := pumped.NewExecutionEncoder()
+//	pumped.RegisterTagCodec(enc, userTag, func(u *User) (any, error) { return u.ID, nil })
+//	err := enc.WriteOTLP(file, "orders-service", tree.Query().Run())
+//
+// Retention is configured per scope. By default the last 1000 nodes are
+// kept:
+//
+//	scope := pumped.NewScope(
+//	    pumped.WithExecutionTreeMaxNodes(10_000),
+//	    pumped.WithExecutionTreeMaxAge(time.Hour),
+//	    pumped.WithExecutionTreeRetention(isFailure, 24*time.Hour),  // keep failures longer
+//	    pumped.WithExecutionTreeEvictionHandler(shipToStorage),
+//	)
+//
+// Use WithExecutionTreeDisabled to skip recording entirely on hot paths.
+//
+// # Scheduling
+//
+// A Scheduler runs flows on intervals or cron expressions inside a scope
+// and stops when the scope is disposed:
+//
+//	scheduler := pumped.NewScheduler()
+//	sched, _ := pumped.Resolve(scope, scheduler)
+//
+//	pumped.ScheduleFlow(sched, "health", checkFlow, pumped.Every(30*time.Second),
+//	    pumped.WithJitter(5*time.Second),
+//	    pumped.WithOverlap(pumped.OverlapSkip),
+//	)
+//	pumped.ScheduleFlow(sched, "report", reportFlow, pumped.MustParseCron("0 9 * * mon-fri"))
+//
+// Each run is a root execution tagged with ScheduleName and ScheduledTime.
+// WithSchedulerClock injects a clock so tests can advance time by hand.
+//
+// # Job Queue
+//
+// A scope created with WithJobQueue runs flows in the background on a
+// bounded worker pool:
+//
+//	store, _ := pumped.NewFileJobStore("jobs.log", pumped.WithDeadJobRetention(7*24*time.Hour))
+//	scope := pumped.NewScope(pumped.WithJobQueue(
+//	    pumped.WithQueueWorkers(8),
+//	    pumped.WithQueueStore(store),
+//	    pumped.WithQueueFlows(sendEmail),
+//	    pumped.WithFlowConcurrency("sendEmail", 2),
+//	    pumped.WithQueueRetry(pumped.RetryPolicy{
+//	        MaxAttempts: 5,
+//	        Backoff:     pumped.ExponentialBackoff(time.Second, time.Minute),
+//	    }),
+//	))
+//
+//	id, _ := scope.Enqueue(sendEmail, Email{To: "ada@example.com"}, pumped.WithPriority(10))
+//	job, _ := scope.Job(id)
+//
+// Flows read their input with JobInput. Jobs that exhaust their attempts
+// become dead letters. Pending jobs in a persistent store resume when the
+// next scope using it is created. Other backends, such as a database,
+// plug in by implementing JobStore.
+//
 // # Parallel Execution
 //
 // Execute multiple flows concurrently:
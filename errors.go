@@ -1,22 +1,59 @@
 package pumped
 
 import (
+	"errors"
 	"fmt"
 	"runtime/debug"
+	"strings"
 )
 
+// ErrTagNotFound is returned by typed tag accessors when a tag is absent
+var ErrTagNotFound = errors.New("tag not found")
+
+// ErrRejected is matched by errors that refuse an execution before its
+// factory runs. Such executions are recorded with ExecutionStatusRejected.
+var ErrRejected = errors.New("execution rejected")
+
+// ErrTransientCleanup is returned when a transient value that registers
+// cleanups is resolved without a requester to own them. The cleanups run
+// before the error is returned.
+var ErrTransientCleanup = errors.New("transient value with cleanups needs a requester")
+
+// TagTypeError reports a tag value whose type does not match the tag
+type TagTypeError struct {
+	Key      string
+	Expected string
+	Actual   string
+}
+
+func (e *TagTypeError) Error() string {
+	return fmt.Sprintf("tag %q: expected value of type %s, got %s", e.Key, e.Expected, e.Actual)
+}
+
+// ResolveError reports a failed resolution. Errors from dependencies keep
+// the ResolveError of the executor that failed and extend its Path.
 type ResolveError struct {
 	ExecutorID AnyExecutor
 	Cause      error
 	Context    string
+
+	// Path lists the executors from the requested root to ExecutorID
+	Path []AnyExecutor
+
+	// StackTrace is captured for panics, by CreateResolveError, and for
+	// every resolution failure in scopes created WithStackTraces
 	StackTrace []byte
 }
 
 func (e *ResolveError) Error() string {
+	msg := fmt.Sprintf("resolve error in executor %v", e.ExecutorID)
 	if e.Context != "" {
-		return fmt.Sprintf("resolve error in executor %v during %s: %v", e.ExecutorID, e.Context, e.Cause)
+		msg += " during " + e.Context
+	}
+	if len(e.Path) > 1 {
+		msg += " (path: " + formatPath(e.Path) + ")"
 	}
-	return fmt.Sprintf("resolve error in executor %v: %v", e.ExecutorID, e.Cause)
+	return fmt.Sprintf("%s: %v", msg, e.Cause)
 }
 
 func (e *ResolveError) Unwrap() error {
@@ -39,11 +76,73 @@ func SafeTypeAssertion[T any](value any) (T, error) {
 	return typed, nil
 }
 
+// CreateResolveError returns a ResolveError for executor with a stack trace
+// captured at the call
 func CreateResolveError(executor AnyExecutor, cause error, context string) *ResolveError {
+	re := newResolveError(executor, cause, context)
+	re.StackTrace = debug.Stack()
+	return re
+}
+
+// newResolveError returns a ResolveError without a stack trace; the scope
+// adds one when created WithStackTraces
+func newResolveError(executor AnyExecutor, cause error, context string) *ResolveError {
 	return &ResolveError{
 		ExecutorID: executor,
 		Cause:      cause,
 		Context:    context,
-		StackTrace: debug.Stack(),
+		Path:       []AnyExecutor{executor},
 	}
 }
+
+// resolveError attributes err to exec. A ResolveError from a dependency is
+// copied with exec prepended to its path; any other error is wrapped.
+func (s *Scope) resolveError(exec AnyExecutor, err error, context string) *ResolveError {
+	if re, ok := err.(*ResolveError); ok {
+		if len(re.Path) > 0 && re.Path[0] == exec {
+			return re
+		}
+		parent := *re
+		parent.Path = append([]AnyExecutor{exec}, re.Path...)
+		return &parent
+	}
+
+	re := newResolveError(exec, err, context)
+	var inner *ResolveError
+	if errors.As(err, &inner) && len(inner.Path) > 0 {
+		if inner.Path[0] == exec {
+			re.Path = inner.Path
+		} else {
+			re.Path = append(re.Path, inner.Path...)
+		}
+	}
+	if s.stackTraces {
+		re.StackTrace = debug.Stack()
+	}
+	return re
+}
+
+func formatPath(path []AnyExecutor) string {
+	names := make([]string, len(path))
+	for i, exec := range path {
+		if name, ok := executorNameTag.Get(exec); ok {
+			names[i] = name
+		} else {
+			names[i] = fmt.Sprintf("%p", exec)
+		}
+	}
+	return strings.Join(names, " -> ")
+}
+
+// LifetimeError reports an executor that depends on a shorter-lived one
+type LifetimeError struct {
+	Executor           AnyExecutor
+	Lifetime           Lifetime
+	Dependency         AnyExecutor
+	DependencyLifetime Lifetime
+}
+
+func (e *LifetimeError) Error() string {
+	return fmt.Sprintf("%s executor %v cannot depend on %s executor %v",
+		e.Lifetime, e.Executor, e.DependencyLifetime, e.Dependency)
+}
@@ -0,0 +1,86 @@
+package pumped
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolveErrorPath(t *testing.T) {
+	errDown := errors.New("connection refused")
+
+	db := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "", errDown
+	}, WithTag(executorNameTag, "db"))
+	repo := Derive1(db, func(ctx *ResolveCtx, d *Controller[string]) (string, error) {
+		return d.Get()
+	}, WithTag(executorNameTag, "repo"))
+	service := Derive1(repo, func(ctx *ResolveCtx, r *Controller[string]) (string, error) {
+		return r.Get()
+	}, WithTag(executorNameTag, "service"))
+
+	scope := NewScope()
+	defer scope.Dispose()
+
+	_, err := Resolve(scope, service)
+	if !errors.Is(err, errDown) {
+		t.Fatalf("expected cause to be preserved, got %v", err)
+	}
+
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) {
+		t.Fatalf("expected ResolveError, got %T", err)
+	}
+	if resolveErr.ExecutorID != AnyExecutor(db) || resolveErr.Context != "factory" {
+		t.Errorf("expected factory error from db, got %v", resolveErr)
+	}
+
+	want := []AnyExecutor{service, repo, db}
+	if len(resolveErr.Path) != len(want) {
+		t.Fatalf("expected path of %d, got %d", len(want), len(resolveErr.Path))
+	}
+	for i := range want {
+		if resolveErr.Path[i] != want[i] {
+			t.Errorf("path[%d] mismatch", i)
+		}
+	}
+	if !strings.Contains(err.Error(), "service -> repo -> db") {
+		t.Errorf("expected path in message, got %q", err.Error())
+	}
+	if resolveErr.StackTrace != nil {
+		t.Error("expected no stack trace by default")
+	}
+
+	// Resolving the dependency directly reports a path of its own
+	_, err = Resolve(scope, repo)
+	if !errors.As(err, &resolveErr) || len(resolveErr.Path) != 2 {
+		t.Errorf("expected path repo -> db, got %v", err)
+	}
+}
+
+func TestResolveErrorStackTraces(t *testing.T) {
+	failing := Provide(func(ctx *ResolveCtx) (int, error) {
+		return 0, errors.New("failed")
+	})
+
+	scope := NewScope(WithStackTraces())
+	defer scope.Dispose()
+
+	_, err := Resolve(scope, failing)
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) {
+		t.Fatalf("expected ResolveError, got %v", err)
+	}
+	if len(resolveErr.StackTrace) == 0 {
+		t.Error("expected stack trace with WithStackTraces")
+	}
+}
+
+func TestCreateResolveErrorCapturesStack(t *testing.T) {
+	exec := Provide(func(ctx *ResolveCtx) (int, error) { return 0, nil })
+
+	re := CreateResolveError(exec, errors.New("bad"), "custom")
+	if !strings.Contains(string(re.StackTrace), "errors_test.go") {
+		t.Error("expected CreateResolveError to capture the caller's stack")
+	}
+}
@@ -0,0 +1,309 @@
+package pumped
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind identifies a lifecycle event
+type EventKind string
+
+const (
+	EventFlowStarted         EventKind = "flow.started"
+	EventFlowFinished        EventKind = "flow.finished"
+	EventFlowFailed          EventKind = "flow.failed"
+	EventFlowPanicked        EventKind = "flow.panicked"
+	EventFlowCancelled       EventKind = "flow.cancelled"
+	EventFlowRejected        EventKind = "flow.rejected"
+	EventExecutorResolved    EventKind = "executor.resolved"
+	EventExecutorUpdated     EventKind = "executor.updated"
+	EventExecutorInvalidated EventKind = "executor.invalidated"
+	EventExecutorCleanedUp   EventKind = "executor.cleaned_up"
+)
+
+// IsFlow reports whether the event describes a flow execution
+func (k EventKind) IsFlow() bool {
+	switch k {
+	case EventFlowStarted, EventFlowFinished, EventFlowFailed, EventFlowPanicked, EventFlowCancelled, EventFlowRejected:
+		return true
+	}
+	return false
+}
+
+// Event is a lifecycle notification published by a scope.
+//
+// Seq increases by one for every event published by the scope, and each
+// subscriber receives its events in Seq order. Events published from the
+// same goroutine keep their program order, so a flow's Started event always
+// precedes its terminal event, and the events of sub-flows fall between
+// them. Events from concurrent goroutines interleave in publication order.
+type Event struct {
+	Kind EventKind
+	Seq  uint64
+	Time time.Time
+
+	// Flow events
+	ExecutionID string
+	ParentID    string
+	FlowName    string
+
+	// Executor events
+	Executor AnyExecutor
+
+	// Err is set for failed, panicked, cancelled and rejected flows
+	Err error
+}
+
+// EventOption configures an event subscription
+type EventOption func(*eventFilter)
+
+type eventFilter struct {
+	kinds     map[EventKind]bool
+	flowNames map[string]bool
+	execTags  []any
+	buffer    int
+}
+
+// EventKinds only delivers events of the given kinds
+func EventKinds(kinds ...EventKind) EventOption {
+	return func(f *eventFilter) {
+		if f.kinds == nil {
+			f.kinds = make(map[EventKind]bool)
+		}
+		for _, k := range kinds {
+			f.kinds[k] = true
+		}
+	}
+}
+
+// EventFlowNames only delivers flow events for the named flows. Unless
+// EventExecutorTag is also used, executor events are not delivered.
+func EventFlowNames(names ...string) EventOption {
+	return func(f *eventFilter) {
+		if f.flowNames == nil {
+			f.flowNames = make(map[string]bool)
+		}
+		for _, n := range names {
+			f.flowNames[n] = true
+		}
+	}
+}
+
+// EventExecutorTag only delivers executor events for executors carrying
+// tag. Unless EventFlowNames is also used, flow events are not delivered.
+func EventExecutorTag(tag any) EventOption {
+	return func(f *eventFilter) {
+		f.execTags = append(f.execTags, tag)
+	}
+}
+
+// EventBuffer sets how many undelivered events a subscription holds before
+// it starts dropping. The default is 256.
+func EventBuffer(n int) EventOption {
+	return func(f *eventFilter) {
+		f.buffer = n
+	}
+}
+
+func (f *eventFilter) match(ev Event) bool {
+	if f.kinds != nil && !f.kinds[ev.Kind] {
+		return false
+	}
+	if f.flowNames == nil && f.execTags == nil {
+		return true
+	}
+
+	if ev.Kind.IsFlow() {
+		return f.flowNames != nil && f.flowNames[ev.FlowName]
+	}
+
+	if ev.Executor == nil {
+		return false
+	}
+	for _, tag := range f.execTags {
+		if _, ok := ev.Executor.GetTag(tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// EventSubscription receives events on C until it is closed or its context
+// is cancelled. Publishing never blocks: when the buffer is full the event
+// is dropped and counted.
+type EventSubscription struct {
+	C <-chan Event
+
+	ch      chan Event
+	stop    chan struct{}
+	filter  eventFilter
+	bus     *eventBus
+	dropped atomic.Uint64
+	closed  bool
+}
+
+// Dropped returns how many events were dropped because the buffer was full
+func (sub *EventSubscription) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
+// Close stops delivery and closes C
+func (sub *EventSubscription) Close() {
+	sub.bus.remove(sub)
+}
+
+type eventBus struct {
+	mu     sync.Mutex
+	seq    uint64
+	subs   map[*EventSubscription]struct{}
+	active atomic.Int32
+}
+
+func (b *eventBus) add(sub *EventSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subs == nil {
+		b.subs = make(map[*EventSubscription]struct{})
+	}
+	b.subs[sub] = struct{}{}
+	b.active.Add(1)
+}
+
+func (b *eventBus) remove(sub *EventSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subs, sub)
+	b.active.Add(-1)
+	close(sub.ch)
+	close(sub.stop)
+}
+
+func (b *eventBus) publish(ev Event) {
+	if b.active.Load() == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	ev.Seq = b.seq
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	for sub := range b.subs {
+		if !sub.filter.match(ev) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Events subscribes to the scope's lifecycle events. The subscription is
+// closed when ctx is done or Close is called.
+func (s *Scope) Events(ctx context.Context, opts ...EventOption) *EventSubscription {
+	filter := eventFilter{buffer: 256}
+	for _, opt := range opts {
+		opt(&filter)
+	}
+
+	ch := make(chan Event, filter.buffer)
+	sub := &EventSubscription{
+		C:      ch,
+		ch:     ch,
+		stop:   make(chan struct{}),
+		filter: filter,
+		bus:    &s.root().events,
+	}
+	sub.bus.add(sub)
+
+	if done := ctx.Done(); done != nil {
+		go func() {
+			select {
+			case <-done:
+				sub.Close()
+			case <-sub.stop:
+			}
+		}()
+	}
+
+	return sub
+}
+
+// Subscribe calls fn for every matching event, one at a time and in order,
+// from a dedicated goroutine. The returned subscription stops delivery when
+// closed.
+func (s *Scope) Subscribe(fn func(Event), opts ...EventOption) *EventSubscription {
+	sub := s.Events(context.Background(), opts...)
+	go func() {
+		for ev := range sub.C {
+			fn(ev)
+		}
+	}()
+	return sub
+}
+
+func (s *Scope) publish(ev Event) {
+	s.root().events.publish(ev)
+}
+
+func (s *Scope) publishExecutor(kind EventKind, exec AnyExecutor) {
+	s.publish(Event{Kind: kind, Executor: exec})
+}
+
+func (e *ExecutionCtx) publish(kind EventKind) {
+	ev := Event{
+		Kind:        kind,
+		ExecutionID: e.id,
+	}
+	if e.parent != nil {
+		ev.ParentID = e.parent.id
+	}
+	if name, ok := e.Get(flowNameTag); ok {
+		ev.FlowName, _ = name.(string)
+	}
+	if kind != EventFlowStarted {
+		if err, ok := e.Get(errorTag); ok {
+			ev.Err, _ = err.(error)
+		}
+	}
+	e.scope.publish(ev)
+}
+
+// publishEnd publishes the terminal event matching the execution status
+func (e *ExecutionCtx) publishEnd() {
+	status, _ := e.Get(statusTag)
+	switch status {
+	case ExecutionStatusSuccess:
+		e.publish(EventFlowFinished)
+	case ExecutionStatusCancelled:
+		e.publish(EventFlowCancelled)
+	case ExecutionStatusRejected:
+		e.publish(EventFlowRejected)
+	default:
+		if _, panicked := e.Get(panicStackTag); panicked {
+			e.publish(EventFlowPanicked)
+		} else {
+			e.publish(EventFlowFailed)
+		}
+	}
+}
+
+// abort ends an execution that stops before its factory runs
+func (e *ExecutionCtx) abort() {
+	e.closeCleanups()
+	e.publishEnd()
+}
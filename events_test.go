@@ -0,0 +1,265 @@
+package pumped
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func collectEvents(t *testing.T, sub *EventSubscription, n int) []Event {
+	t.Helper()
+
+	var events []Event
+	timeout := time.After(time.Second)
+	for len(events) < n {
+		select {
+		case ev := <-sub.C:
+			events = append(events, ev)
+		case <-timeout:
+			t.Fatalf("expected %d events, got %d: %v", n, len(events), events)
+		}
+	}
+	return events
+}
+
+func TestEventsFlowLifecycleOrdering(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	sub := scope.Events(context.Background(), EventKinds(
+		EventFlowStarted, EventFlowFinished, EventFlowFailed, EventFlowPanicked,
+	))
+	defer sub.Close()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	child := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 0, errors.New("child failed")
+	}, WithFlowTag(FlowName(), "child"))
+
+	panicking := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		panic("boom")
+	}, WithFlowTag(FlowName(), "panicking"))
+
+	parent := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		Exec1(execCtx, child)
+		Exec1(execCtx, panicking)
+		return 1, nil
+	}, WithFlowTag(FlowName(), "parent"))
+
+	_, execNode, err := Exec(scope, context.Background(), parent)
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+
+	events := collectEvents(t, sub, 6)
+	expected := []struct {
+		kind EventKind
+		name string
+	}{
+		{EventFlowStarted, "parent"},
+		{EventFlowStarted, "child"},
+		{EventFlowFailed, "child"},
+		{EventFlowStarted, "panicking"},
+		{EventFlowPanicked, "panicking"},
+		{EventFlowFinished, "parent"},
+	}
+
+	for i, want := range expected {
+		ev := events[i]
+		if ev.Kind != want.kind || ev.FlowName != want.name {
+			t.Errorf("event %d: expected %s %s, got %s %s", i, want.kind, want.name, ev.Kind, ev.FlowName)
+		}
+		if i > 0 && ev.Seq <= events[i-1].Seq {
+			t.Errorf("event %d: seq %d not after %d", i, ev.Seq, events[i-1].Seq)
+		}
+	}
+
+	if events[0].ExecutionID != execNode.ID() {
+		t.Errorf("expected parent execution ID %s, got %s", execNode.ID(), events[0].ExecutionID)
+	}
+	if events[1].ParentID != execNode.ID() {
+		t.Errorf("expected child parent ID %s, got %s", execNode.ID(), events[1].ParentID)
+	}
+	if events[2].Err == nil || events[2].Err.Error() != "child failed" {
+		t.Errorf("expected child error, got %v", events[2].Err)
+	}
+}
+
+func TestEventsCancelledFlow(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	sub := scope.Events(context.Background(), EventFlowNames("slow"))
+	defer sub.Close()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		<-execCtx.Context().Done()
+		return 0, execCtx.Context().Err()
+	}, WithFlowTag(FlowName(), "slow"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	Exec(scope, ctx, flow)
+
+	events := collectEvents(t, sub, 2)
+	if events[0].Kind != EventFlowStarted || events[1].Kind != EventFlowCancelled {
+		t.Fatalf("expected started then cancelled, got %s then %s", events[0].Kind, events[1].Kind)
+	}
+	if !errors.Is(events[1].Err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", events[1].Err)
+	}
+}
+
+func TestEventsExecutorLifecycle(t *testing.T) {
+	scope := NewScope()
+
+	counter := Provide(func(ctx *ResolveCtx) (int, error) {
+		ctx.OnCleanup(func() error { return nil })
+		return 1, nil
+	})
+	doubled := Derive1(counter.Reactive(), func(ctx *ResolveCtx, c *Controller[int]) (int, error) {
+		v, _ := c.Get()
+		return v * 2, nil
+	})
+
+	sub := scope.Events(context.Background(), EventKinds(
+		EventExecutorResolved, EventExecutorUpdated, EventExecutorInvalidated, EventExecutorCleanedUp,
+	))
+	defer sub.Close()
+
+	if _, err := Resolve(scope, doubled); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if _, err := Resolve(scope, doubled); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if err := Update(context.Background(), scope, counter, 2); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	scope.Dispose()
+
+	events := collectEvents(t, sub, 5)
+	expected := []struct {
+		kind EventKind
+		exec AnyExecutor
+	}{
+		{EventExecutorResolved, counter},
+		{EventExecutorResolved, doubled},
+		{EventExecutorCleanedUp, counter},
+		{EventExecutorUpdated, counter},
+		{EventExecutorInvalidated, doubled},
+	}
+	for i, want := range expected {
+		if events[i].Kind != want.kind || events[i].Executor != want.exec {
+			t.Errorf("event %d: expected %s, got %s", i, want.kind, events[i].Kind)
+		}
+	}
+}
+
+func TestEventsExecutorTagFilter(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	watched := NewTag[bool]("watched")
+	a := Provide(func(ctx *ResolveCtx) (int, error) { return 1, nil }, WithTag(watched, true))
+	b := Provide(func(ctx *ResolveCtx) (int, error) { return 2, nil })
+
+	sub := scope.Events(context.Background(), EventExecutorTag(watched))
+	defer sub.Close()
+
+	Resolve(scope, b)
+	Resolve(scope, a)
+
+	events := collectEvents(t, sub, 1)
+	if events[0].Executor != a {
+		t.Fatalf("expected event for tagged executor")
+	}
+
+	select {
+	case ev := <-sub.C:
+		t.Fatalf("unexpected event %s", ev.Kind)
+	default:
+	}
+}
+
+func TestEventsDropWhenBufferFull(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	sub := scope.Events(context.Background(), EventBuffer(2))
+	defer sub.Close()
+
+	for i := 0; i < 5; i++ {
+		exec := Provide(func(ctx *ResolveCtx) (int, error) { return i, nil })
+		Resolve(scope, exec)
+	}
+
+	if sub.Dropped() != 3 {
+		t.Errorf("expected 3 dropped events, got %d", sub.Dropped())
+	}
+
+	events := collectEvents(t, sub, 2)
+	if events[0].Seq != 1 || events[1].Seq != 2 {
+		t.Errorf("expected first two events to be kept, got seq %d and %d", events[0].Seq, events[1].Seq)
+	}
+}
+
+func TestEventsClosedWithContext(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := scope.Events(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-sub.C:
+		if ok {
+			t.Fatal("expected channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+
+	sub.Close()
+	Resolve(scope, Provide(func(ctx *ResolveCtx) (int, error) { return 1, nil }))
+}
+
+func TestSubscribeDeliversInOrder(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	received := make(chan uint64, 10)
+	sub := scope.Subscribe(func(ev Event) {
+		received <- ev.Seq
+	}, EventKinds(EventExecutorResolved))
+
+	for i := 0; i < 3; i++ {
+		Resolve(scope, Provide(func(ctx *ResolveCtx) (int, error) { return i, nil }))
+	}
+
+	var last uint64
+	for i := 0; i < 3; i++ {
+		select {
+		case seq := <-received:
+			if seq <= last {
+				t.Errorf("expected increasing seq, got %d after %d", seq, last)
+			}
+			last = seq
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+
+	sub.Close()
+}
@@ -0,0 +1,352 @@
+package pumped
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// ExecutionSnapshot is an immutable copy of an ExecutionNode, safe to keep
+// after the node is evicted or updated
+type ExecutionSnapshot struct {
+	ID        string
+	ParentID  string
+	FlowName  string
+	Status    ExecutionStatus
+	StartTime time.Time
+	EndTime   time.Time
+	Duration  time.Duration
+	Err       error
+	Tags      map[any]any
+
+	seq uint64
+}
+
+// Snapshot copies the node's well-known tags into typed fields and the rest
+// into a detached tag map
+func (n *ExecutionNode) Snapshot() ExecutionSnapshot {
+	snap := n.summary()
+	snap.Tags = n.GetAllTags()
+	return snap
+}
+
+// summary reads the node's well-known tags without copying the tag map;
+// they are never among the late tags
+func (n *ExecutionNode) summary() ExecutionSnapshot {
+	snap := ExecutionSnapshot{
+		ID:       n.ID,
+		ParentID: n.ParentID,
+		seq:      n.seq,
+	}
+	snap.FlowName, _ = n.Tags[flowNameTag].(string)
+	snap.Status, _ = n.Tags[statusTag].(ExecutionStatus)
+	snap.StartTime, _ = n.Tags[startTimeTag].(time.Time)
+	snap.EndTime, _ = n.Tags[endTimeTag].(time.Time)
+	snap.Err, _ = n.Tags[errorTag].(error)
+	if !snap.StartTime.IsZero() && !snap.EndTime.IsZero() {
+		snap.Duration = snap.EndTime.Sub(snap.StartTime)
+	}
+	return snap
+}
+
+// QuerySort selects the ordering of query results
+type QuerySort int
+
+const (
+	// SortByRecorded orders executions by the time they were recorded
+	SortByRecorded QuerySort = iota
+	// SortByStartTime orders executions by StartTime
+	SortByStartTime
+	// SortByEndTime orders executions by EndTime
+	SortByEndTime
+	// SortByDuration orders executions by EndTime - StartTime
+	SortByDuration
+)
+
+// ExecutionQuery selects executions from an ExecutionTree. Flow name,
+// status and start time filters use the tree's indexes; the remaining
+// filters are applied to the indexed candidates.
+type ExecutionQuery struct {
+	tree *ExecutionTree
+
+	flowNames   []string
+	statuses    []ExecutionStatus
+	rootsOnly   bool
+	startFrom   time.Time
+	startTo     time.Time
+	endFrom     time.Time
+	endTo       time.Time
+	minDuration time.Duration
+	maxDuration time.Duration
+	errMatchers []func(error) bool
+
+	sortBy     QuerySort
+	descending bool
+	offset     int
+	limit      int
+}
+
+// Query starts a new query over the tree
+func (t *ExecutionTree) Query() *ExecutionQuery {
+	return &ExecutionQuery{tree: t}
+}
+
+// FlowName keeps executions of any of the named flows
+func (q *ExecutionQuery) FlowName(names ...string) *ExecutionQuery {
+	q.flowNames = append(q.flowNames, names...)
+	return q
+}
+
+// Status keeps executions in any of the given statuses
+func (q *ExecutionQuery) Status(statuses ...ExecutionStatus) *ExecutionQuery {
+	q.statuses = append(q.statuses, statuses...)
+	return q
+}
+
+// RootsOnly keeps top-level executions started with Exec
+func (q *ExecutionQuery) RootsOnly() *ExecutionQuery {
+	q.rootsOnly = true
+	return q
+}
+
+// StartedBetween keeps executions whose StartTime is within [from, to].
+// A zero bound is open.
+func (q *ExecutionQuery) StartedBetween(from, to time.Time) *ExecutionQuery {
+	q.startFrom, q.startTo = from, to
+	return q
+}
+
+// EndedBetween keeps executions whose EndTime is within [from, to].
+// A zero bound is open.
+func (q *ExecutionQuery) EndedBetween(from, to time.Time) *ExecutionQuery {
+	q.endFrom, q.endTo = from, to
+	return q
+}
+
+// MinDuration keeps executions that took at least d
+func (q *ExecutionQuery) MinDuration(d time.Duration) *ExecutionQuery {
+	q.minDuration = d
+	return q
+}
+
+// MaxDuration keeps executions that took at most d
+func (q *ExecutionQuery) MaxDuration(d time.Duration) *ExecutionQuery {
+	q.maxDuration = d
+	return q
+}
+
+// ErrorIs keeps executions whose error matches target with errors.Is
+func (q *ExecutionQuery) ErrorIs(target error) *ExecutionQuery {
+	q.errMatchers = append(q.errMatchers, func(err error) bool {
+		return errors.Is(err, target)
+	})
+	return q
+}
+
+// ErrorAs keeps executions whose error chain contains an error assignable
+// to the type target points to, as with errors.As. Target itself is not
+// modified.
+func (q *ExecutionQuery) ErrorAs(target any) *ExecutionQuery {
+	typ := reflect.TypeOf(target)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		panic("pumped: ErrorAs target must be a non-nil pointer")
+	}
+	elem := typ.Elem()
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	if elem.Kind() != reflect.Interface && !elem.Implements(errorType) {
+		panic("pumped: ErrorAs target must point to an interface or a type implementing error")
+	}
+
+	q.errMatchers = append(q.errMatchers, func(err error) bool {
+		return errors.As(err, reflect.New(elem).Interface())
+	})
+	return q
+}
+
+// OrderBy sets the result ordering; the default is recording order
+func (q *ExecutionQuery) OrderBy(field QuerySort, descending bool) *ExecutionQuery {
+	q.sortBy = field
+	q.descending = descending
+	return q
+}
+
+// Offset skips the first n matches
+func (q *ExecutionQuery) Offset(n int) *ExecutionQuery {
+	q.offset = n
+	return q
+}
+
+// Limit returns at most n matches; zero means no limit
+func (q *ExecutionQuery) Limit(n int) *ExecutionQuery {
+	q.limit = n
+	return q
+}
+
+// Count returns the number of matches, ignoring offset and limit
+func (q *ExecutionQuery) Count() int {
+	return len(q.match(false))
+}
+
+// Run returns snapshots of the matching executions
+func (q *ExecutionQuery) Run() []ExecutionSnapshot {
+	matches := q.match(true)
+	q.sort(matches)
+
+	if q.offset >= len(matches) {
+		return []ExecutionSnapshot{}
+	}
+	matches = matches[q.offset:]
+	if q.limit > 0 && q.limit < len(matches) {
+		matches = matches[:q.limit]
+	}
+	return matches
+}
+
+func (q *ExecutionQuery) match(withTags bool) []ExecutionSnapshot {
+	t := q.tree
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	// Filter on the typed fields first and copy tags of matches only
+	var result []ExecutionSnapshot
+	for _, node := range q.candidatesLocked() {
+		if q.rootsOnly && node.ParentID != "" {
+			continue
+		}
+		snap := node.summary()
+		if !q.accept(snap) {
+			continue
+		}
+		if withTags {
+			snap.Tags = node.GetAllTags()
+		}
+		result = append(result, snap)
+	}
+	return result
+}
+
+func (q *ExecutionQuery) candidatesLocked() []*ExecutionNode {
+	t := q.tree
+
+	var ids map[string]struct{}
+	if len(q.flowNames) > 0 {
+		ids = make(map[string]struct{})
+		for _, name := range q.flowNames {
+			for id := range t.byName[name] {
+				ids[id] = struct{}{}
+			}
+		}
+	}
+	if len(q.statuses) > 0 {
+		byStatus := make(map[string]struct{})
+		for _, status := range q.statuses {
+			for id := range t.byStatus[status] {
+				if ids == nil {
+					byStatus[id] = struct{}{}
+				} else if _, ok := ids[id]; ok {
+					byStatus[id] = struct{}{}
+				}
+			}
+		}
+		ids = byStatus
+	}
+
+	// A start time range narrows the candidates to a slice of the start
+	// index; use it when it is smaller than the name and status matches
+	if !q.startFrom.IsZero() || !q.startTo.IsZero() {
+		started := t.startedWithin(q.startFrom, q.startTo)
+		if ids == nil || len(started) < len(ids) {
+			nodes := make([]*ExecutionNode, 0, len(started))
+			for _, node := range started {
+				if ids != nil {
+					if _, ok := ids[node.ID]; !ok {
+						continue
+					}
+				}
+				nodes = append(nodes, node)
+			}
+			return nodes
+		}
+	}
+
+	if ids == nil {
+		nodes := make([]*ExecutionNode, 0, len(t.nodes))
+		for _, node := range t.nodes {
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	nodes := make([]*ExecutionNode, 0, len(ids))
+	for id := range ids {
+		if node := t.nodes[id]; node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func (q *ExecutionQuery) accept(snap ExecutionSnapshot) bool {
+	if q.rootsOnly && snap.ParentID != "" {
+		return false
+	}
+	if !inRange(snap.StartTime, q.startFrom, q.startTo) || !inRange(snap.EndTime, q.endFrom, q.endTo) {
+		return false
+	}
+	if q.minDuration > 0 && snap.Duration < q.minDuration {
+		return false
+	}
+	if q.maxDuration > 0 && snap.Duration > q.maxDuration {
+		return false
+	}
+	for _, matches := range q.errMatchers {
+		if snap.Err == nil || !matches(snap.Err) {
+			return false
+		}
+	}
+	return true
+}
+
+func inRange(t, from, to time.Time) bool {
+	if from.IsZero() && to.IsZero() {
+		return true
+	}
+	if t.IsZero() {
+		return false
+	}
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && t.After(to) {
+		return false
+	}
+	return true
+}
+
+func (q *ExecutionQuery) sort(snaps []ExecutionSnapshot) {
+	less := func(a, b ExecutionSnapshot) bool {
+		switch q.sortBy {
+		case SortByStartTime:
+			if !a.StartTime.Equal(b.StartTime) {
+				return a.StartTime.Before(b.StartTime)
+			}
+		case SortByEndTime:
+			if !a.EndTime.Equal(b.EndTime) {
+				return a.EndTime.Before(b.EndTime)
+			}
+		case SortByDuration:
+			if a.Duration != b.Duration {
+				return a.Duration < b.Duration
+			}
+		}
+		return a.seq < b.seq
+	}
+
+	sort.SliceStable(snaps, func(i, j int) bool {
+		if q.descending {
+			return less(snaps[j], snaps[i])
+		}
+		return less(snaps[i], snaps[j])
+	})
+}
@@ -0,0 +1,383 @@
+package pumped
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ExecutionTree records finished flow executions. Roots are kept in the
+// order they finished and are evicted together with their subtrees.
+type ExecutionTree struct {
+	mu       sync.RWMutex
+	nodes    map[string]*ExecutionNode
+	byParent map[string][]string
+	roots    []string
+	seq      uint64
+
+	// secondary indexes used by Query
+	byName   map[string]map[string]struct{}
+	byStatus map[ExecutionStatus]map[string]struct{}
+	byStart  []*ExecutionNode // ordered by start time, then recording order
+
+	limit        int
+	maxAge       time.Duration
+	retain       func(*ExecutionNode) bool
+	retainMaxAge time.Duration
+	onEvict      func(*ExecutionNode)
+	disabled     bool
+	now          func() time.Time
+}
+
+func newExecutionTree(limit int) *ExecutionTree {
+	return &ExecutionTree{
+		nodes:    make(map[string]*ExecutionNode),
+		byParent: make(map[string][]string),
+		roots:    []string{},
+		byName:   make(map[string]map[string]struct{}),
+		byStatus: make(map[ExecutionStatus]map[string]struct{}),
+		limit:    limit,
+		now:      time.Now,
+	}
+}
+
+// emptyCopy returns an empty tree with the same limits, retention and
+// eviction hook
+func (t *ExecutionTree) emptyCopy() *ExecutionTree {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tree := newExecutionTree(t.limit)
+	tree.maxAge = t.maxAge
+	tree.retain = t.retain
+	tree.retainMaxAge = t.retainMaxAge
+	tree.onEvict = t.onEvict
+	tree.disabled = t.disabled
+	tree.now = t.now
+	return tree
+}
+
+// WithExecutionTreeMaxNodes returns an option that caps the number of nodes
+// kept in the execution tree. Zero or less keeps every node.
+func WithExecutionTreeMaxNodes(n int) ScopeOption {
+	return func(s *Scope) {
+		s.execTree.limit = n
+	}
+}
+
+// WithExecutionTreeMaxAge returns an option that evicts executions recorded
+// longer ago than d
+func WithExecutionTreeMaxAge(d time.Duration) ScopeOption {
+	return func(s *Scope) {
+		s.execTree.maxAge = d
+	}
+}
+
+// WithExecutionTreeRetention returns an option that keeps executions longer
+// when any node in their tree matches keep, e.g. failures. Retained trees
+// survive node-count eviction while others remain and expire after maxAge
+// instead of the regular max age; zero keeps them until the node limit
+// forces them out.
+func WithExecutionTreeRetention(keep func(*ExecutionNode) bool, maxAge time.Duration) ScopeOption {
+	return func(s *Scope) {
+		s.execTree.retain = keep
+		s.execTree.retainMaxAge = maxAge
+	}
+}
+
+// WithExecutionTreeEvictionHandler returns an option that is called with
+// every node evicted from the tree, after it has been removed
+func WithExecutionTreeEvictionHandler(fn func(*ExecutionNode)) ScopeOption {
+	return func(s *Scope) {
+		s.execTree.onEvict = fn
+	}
+}
+
+// WithExecutionTreeDisabled returns an option that stops recording
+// executions, for hot paths that do not need history
+func WithExecutionTreeDisabled() ScopeOption {
+	return func(s *Scope) {
+		s.execTree.disabled = true
+	}
+}
+
+func (t *ExecutionTree) addNode(node *ExecutionNode) {
+	t.mu.Lock()
+
+	if t.disabled {
+		t.mu.Unlock()
+		return
+	}
+
+	t.seq++
+	node.seq = t.seq
+	node.recordedAt = t.now()
+	t.nodes[node.ID] = node
+	t.index(node)
+
+	if node.ParentID == "" {
+		t.roots = append(t.roots, node.ID)
+	} else {
+		t.byParent[node.ParentID] = append(t.byParent[node.ParentID], node.ID)
+	}
+
+	evicted := t.evictLocked()
+	t.mu.Unlock()
+
+	t.notifyEvicted(evicted)
+}
+
+// Prune applies age-based eviction without waiting for the next execution
+func (t *ExecutionTree) Prune() {
+	t.mu.Lock()
+	evicted := t.evictLocked()
+	t.mu.Unlock()
+
+	t.notifyEvicted(evicted)
+}
+
+func (t *ExecutionTree) notifyEvicted(evicted []*ExecutionNode) {
+	if t.onEvict == nil {
+		return
+	}
+	for _, node := range evicted {
+		t.onEvict(node)
+	}
+}
+
+func (t *ExecutionTree) evictLocked() []*ExecutionNode {
+	var evicted []*ExecutionNode
+
+	if t.maxAge > 0 || t.retainMaxAge > 0 {
+		now := t.now()
+		kept := t.roots[:0]
+		for _, rootID := range t.roots {
+			if t.expired(rootID, now) {
+				evicted = t.removeSubtree(rootID, evicted)
+				continue
+			}
+			kept = append(kept, rootID)
+		}
+		t.roots = kept
+	}
+
+	for t.limit > 0 && len(t.nodes) > t.limit && len(t.roots) > 0 {
+		victim := 0
+		for i, rootID := range t.roots {
+			if !t.retained(rootID) {
+				victim = i
+				break
+			}
+		}
+
+		rootID := t.roots[victim]
+		t.roots = append(t.roots[:victim], t.roots[victim+1:]...)
+		evicted = t.removeSubtree(rootID, evicted)
+	}
+
+	return evicted
+}
+
+func (t *ExecutionTree) expired(rootID string, now time.Time) bool {
+	node := t.nodes[rootID]
+	if node == nil {
+		return true
+	}
+
+	maxAge := t.maxAge
+	if t.retained(rootID) {
+		maxAge = t.retainMaxAge
+	}
+	return maxAge > 0 && now.Sub(node.recordedAt) > maxAge
+}
+
+// retained reports whether any node in the subtree matches the retention
+// predicate
+func (t *ExecutionTree) retained(rootID string) bool {
+	if t.retain == nil {
+		return false
+	}
+
+	found := false
+	t.walkUnlocked(rootID, func(node *ExecutionNode) bool {
+		if t.retain(node) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func (t *ExecutionTree) index(node *ExecutionNode) {
+	if name, ok := node.GetTag(flowNameTag); ok {
+		key := name.(string)
+		if t.byName[key] == nil {
+			t.byName[key] = make(map[string]struct{})
+		}
+		t.byName[key][node.ID] = struct{}{}
+	}
+	if status, ok := node.GetTag(statusTag); ok {
+		key := status.(ExecutionStatus)
+		if t.byStatus[key] == nil {
+			t.byStatus[key] = make(map[string]struct{})
+		}
+		t.byStatus[key][node.ID] = struct{}{}
+	}
+
+	i := t.startPosition(startTimeOf(node), node.seq)
+	t.byStart = append(t.byStart, nil)
+	copy(t.byStart[i+1:], t.byStart[i:])
+	t.byStart[i] = node
+}
+
+func (t *ExecutionTree) unindex(node *ExecutionNode) {
+	if name, ok := node.GetTag(flowNameTag); ok {
+		key := name.(string)
+		delete(t.byName[key], node.ID)
+		if len(t.byName[key]) == 0 {
+			delete(t.byName, key)
+		}
+	}
+	if status, ok := node.GetTag(statusTag); ok {
+		key := status.(ExecutionStatus)
+		delete(t.byStatus[key], node.ID)
+		if len(t.byStatus[key]) == 0 {
+			delete(t.byStatus, key)
+		}
+	}
+
+	i := t.startPosition(startTimeOf(node), node.seq)
+	if i < len(t.byStart) && t.byStart[i] == node {
+		t.byStart = append(t.byStart[:i], t.byStart[i+1:]...)
+	}
+}
+
+// startPosition returns the index in byStart of the first node that starts
+// at or after start and was recorded at or after seq
+func (t *ExecutionTree) startPosition(start time.Time, seq uint64) int {
+	return sort.Search(len(t.byStart), func(i int) bool {
+		other := startTimeOf(t.byStart[i])
+		if !other.Equal(start) {
+			return other.After(start)
+		}
+		return t.byStart[i].seq >= seq
+	})
+}
+
+// startedWithin returns the indexed nodes whose start time is within
+// [from, to]; a zero bound is open
+func (t *ExecutionTree) startedWithin(from, to time.Time) []*ExecutionNode {
+	lo := 0
+	if !from.IsZero() {
+		lo = t.startPosition(from, 0)
+	}
+	hi := len(t.byStart)
+	if !to.IsZero() {
+		hi = t.startPosition(to, math.MaxUint64)
+	}
+	if hi < lo {
+		return nil
+	}
+	return t.byStart[lo:hi]
+}
+
+func startTimeOf(node *ExecutionNode) time.Time {
+	start, _ := node.Tags[startTimeTag].(time.Time)
+	return start
+}
+
+func (t *ExecutionTree) removeSubtree(nodeID string, evicted []*ExecutionNode) []*ExecutionNode {
+	if node := t.nodes[nodeID]; node != nil {
+		t.unindex(node)
+		evicted = append(evicted, node)
+	}
+	delete(t.nodes, nodeID)
+
+	children := t.byParent[nodeID]
+	delete(t.byParent, nodeID)
+
+	for _, childID := range children {
+		evicted = t.removeSubtree(childID, evicted)
+	}
+	return evicted
+}
+
+func (t *ExecutionTree) GetNode(id string) *ExecutionNode {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.nodes[id]
+}
+
+func (t *ExecutionTree) GetChildren(id string) []*ExecutionNode {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	childIDs := t.byParent[id]
+	children := make([]*ExecutionNode, 0, len(childIDs))
+	for _, childID := range childIDs {
+		if node := t.nodes[childID]; node != nil {
+			children = append(children, node)
+		}
+	}
+	return children
+}
+
+func (t *ExecutionTree) GetRoots() []*ExecutionNode {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	roots := make([]*ExecutionNode, 0, len(t.roots))
+	for _, rootID := range t.roots {
+		if node := t.nodes[rootID]; node != nil {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+func (t *ExecutionTree) Filter(predicate func(*ExecutionNode) bool) []*ExecutionNode {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var result []*ExecutionNode
+	for _, node := range t.nodes {
+		if predicate(node) {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+func (t *ExecutionTree) Walk(rootID string, visitor func(*ExecutionNode) bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	node := t.nodes[rootID]
+	if node == nil {
+		return
+	}
+
+	if !visitor(node) {
+		return
+	}
+
+	for _, childID := range t.byParent[rootID] {
+		t.walkUnlocked(childID, visitor)
+	}
+}
+
+func (t *ExecutionTree) walkUnlocked(nodeID string, visitor func(*ExecutionNode) bool) {
+	node := t.nodes[nodeID]
+	if node == nil {
+		return
+	}
+
+	if !visitor(node) {
+		return
+	}
+
+	for _, childID := range t.byParent[nodeID] {
+		t.walkUnlocked(childID, visitor)
+	}
+}
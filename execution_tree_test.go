@@ -0,0 +1,255 @@
+package pumped
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func addTestNode(tree *ExecutionTree, id, parentID string, status ExecutionStatus) {
+	tree.addNode(&ExecutionNode{
+		ID:       id,
+		ParentID: parentID,
+		Tags:     map[any]any{statusTag: status},
+	})
+}
+
+func TestExecutionTree_NodeLimitCountsChildren(t *testing.T) {
+	scope := NewScope(WithExecutionTreeMaxNodes(5))
+	tree := scope.GetExecutionTree()
+
+	for i := 0; i < 3; i++ {
+		root := fmt.Sprintf("root-%d", i)
+		addTestNode(tree, root+"-child", root, ExecutionStatusSuccess)
+		addTestNode(tree, root, "", ExecutionStatusSuccess)
+	}
+
+	if n := len(tree.Filter(func(*ExecutionNode) bool { return true })); n > 5 {
+		t.Errorf("expected at most 5 nodes, got %d", n)
+	}
+
+	roots := tree.GetRoots()
+	if len(roots) != 2 || roots[0].ID != "root-1" || roots[1].ID != "root-2" {
+		t.Errorf("expected roots [root-1 root-2], got %v", roots)
+	}
+	if tree.GetNode("root-0-child") != nil {
+		t.Error("expected evicted root's children to be removed")
+	}
+}
+
+func TestExecutionTree_MaxAgeAndRetention(t *testing.T) {
+	var evicted []string
+	scope := NewScope(
+		WithExecutionTreeMaxAge(time.Minute),
+		WithExecutionTreeRetention(func(node *ExecutionNode) bool {
+			status, _ := node.GetTag(statusTag)
+			return status == ExecutionStatusFailed
+		}, time.Hour),
+		WithExecutionTreeEvictionHandler(func(node *ExecutionNode) {
+			evicted = append(evicted, node.ID)
+		}),
+	)
+	tree := scope.GetExecutionTree()
+
+	now := time.Now()
+	tree.now = func() time.Time { return now }
+
+	addTestNode(tree, "ok-child", "ok", ExecutionStatusSuccess)
+	addTestNode(tree, "ok", "", ExecutionStatusSuccess)
+	addTestNode(tree, "failed-child", "failed", ExecutionStatusFailed)
+	addTestNode(tree, "failed", "", ExecutionStatusSuccess)
+
+	now = now.Add(2 * time.Minute)
+	tree.Prune()
+
+	if tree.GetNode("ok") != nil {
+		t.Error("expected successful execution to expire after max age")
+	}
+	if tree.GetNode("failed") == nil || tree.GetNode("failed-child") == nil {
+		t.Error("expected execution containing a failure to be retained")
+	}
+	if fmt.Sprint(evicted) != "[ok ok-child]" {
+		t.Errorf("expected eviction handler to see [ok ok-child], got %v", evicted)
+	}
+
+	now = now.Add(2 * time.Hour)
+	tree.Prune()
+
+	if tree.GetNode("failed") != nil {
+		t.Error("expected retained execution to expire after retention max age")
+	}
+}
+
+func TestExecutionTree_RetentionSurvivesNodeLimit(t *testing.T) {
+	scope := NewScope(
+		WithExecutionTreeMaxNodes(2),
+		WithExecutionTreeRetention(func(node *ExecutionNode) bool {
+			status, _ := node.GetTag(statusTag)
+			return status == ExecutionStatusFailed
+		}, 0),
+	)
+	tree := scope.GetExecutionTree()
+
+	addTestNode(tree, "failed", "", ExecutionStatusFailed)
+	addTestNode(tree, "ok-1", "", ExecutionStatusSuccess)
+	addTestNode(tree, "ok-2", "", ExecutionStatusSuccess)
+
+	if tree.GetNode("failed") == nil {
+		t.Error("expected failed execution to survive count eviction")
+	}
+	if tree.GetNode("ok-1") != nil {
+		t.Error("expected oldest non-retained execution to be evicted")
+	}
+
+	addTestNode(tree, "failed-2", "", ExecutionStatusFailed)
+	addTestNode(tree, "failed-3", "", ExecutionStatusFailed)
+
+	if tree.GetNode("failed") != nil {
+		t.Error("expected node limit to evict retained executions once nothing else remains")
+	}
+}
+
+func TestExecutionTree_Disabled(t *testing.T) {
+	scope := NewScope(WithExecutionTreeDisabled())
+	defer scope.Dispose()
+
+	flow := Flow1(Provide(func(ctx *ResolveCtx) (int, error) {
+		return 1, nil
+	}), func(execCtx *ExecutionCtx, c *Controller[int]) (int, error) {
+		return 0, errors.New("not recorded")
+	})
+
+	_, _, _ = Exec(scope, context.Background(), flow)
+
+	if roots := scope.GetExecutionTree().GetRoots(); len(roots) != 0 {
+		t.Errorf("expected no recorded executions, got %d", len(roots))
+	}
+}
+
+type queryTestError struct {
+	code int
+}
+
+func (e *queryTestError) Error() string {
+	return fmt.Sprintf("query test error %d", e.code)
+}
+
+func addTimedNode(tree *ExecutionTree, id, name string, status ExecutionStatus, start time.Time, d time.Duration, err error) {
+	tags := map[any]any{
+		flowNameTag:  name,
+		statusTag:    status,
+		startTimeTag: start,
+		endTimeTag:   start.Add(d),
+	}
+	if err != nil {
+		tags[errorTag] = err
+	}
+	tree.addNode(&ExecutionNode{ID: id, Tags: tags})
+}
+
+func TestExecutionTree_Query(t *testing.T) {
+	tree := newExecutionTree(0)
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	notFound := errors.New("not found")
+
+	addTimedNode(tree, "a", "checkout", ExecutionStatusSuccess, base, 10*time.Millisecond, nil)
+	addTimedNode(tree, "b", "checkout", ExecutionStatusFailed, base.Add(time.Second), 300*time.Millisecond, &queryTestError{code: 500})
+	addTimedNode(tree, "c", "search", ExecutionStatusFailed, base.Add(2*time.Second), 50*time.Millisecond, fmt.Errorf("wrapped: %w", notFound))
+	addTimedNode(tree, "d", "checkout", ExecutionStatusFailed, base.Add(3*time.Second), 100*time.Millisecond, notFound)
+	addTimedNode(tree, "e", "checkout", ExecutionStatusSuccess, base.Add(4*time.Second), 500*time.Millisecond, nil)
+
+	ids := func(snaps []ExecutionSnapshot) string {
+		var out []string
+		for _, s := range snaps {
+			out = append(out, s.ID)
+		}
+		return fmt.Sprint(out)
+	}
+
+	if got := ids(tree.Query().FlowName("checkout").Status(ExecutionStatusFailed).Run()); got != "[b d]" {
+		t.Errorf("name+status: expected [b d], got %s", got)
+	}
+	if got := ids(tree.Query().StartedBetween(base.Add(time.Second), base.Add(3*time.Second)).Run()); got != "[b c d]" {
+		t.Errorf("start range: expected [b c d], got %s", got)
+	}
+	if got := ids(tree.Query().MinDuration(100*time.Millisecond).OrderBy(SortByDuration, true).Run()); got != "[e b d]" {
+		t.Errorf("duration sort: expected [e b d], got %s", got)
+	}
+	if got := ids(tree.Query().ErrorIs(notFound).Run()); got != "[c d]" {
+		t.Errorf("ErrorIs: expected [c d], got %s", got)
+	}
+
+	var target *queryTestError
+	if got := ids(tree.Query().ErrorAs(&target).Run()); got != "[b]" {
+		t.Errorf("ErrorAs: expected [b], got %s", got)
+	}
+	if target != nil {
+		t.Error("ErrorAs should not modify the caller's target")
+	}
+
+	page := tree.Query().FlowName("checkout").OrderBy(SortByStartTime, true).Offset(1).Limit(2)
+	if got := ids(page.Run()); got != "[d b]" {
+		t.Errorf("pagination: expected [d b], got %s", got)
+	}
+	if page.Count() != 4 {
+		t.Errorf("expected count 4, got %d", page.Count())
+	}
+
+	snap := tree.Query().FlowName("search").Run()[0]
+	if snap.FlowName != "search" || snap.Status != ExecutionStatusFailed || snap.Duration != 50*time.Millisecond {
+		t.Errorf("unexpected snapshot fields: %+v", snap)
+	}
+	snap.Tags[flowNameTag] = "mutated"
+	if name, _ := tree.GetNode("c").GetTag(flowNameTag); name != "search" {
+		t.Error("mutating a snapshot must not affect the tree")
+	}
+
+	tree.limit = 3
+	addTimedNode(tree, "f", "search", ExecutionStatusSuccess, base.Add(5*time.Second), time.Millisecond, nil)
+	if got := ids(tree.Query().FlowName("checkout").Run()); got != "[d e]" {
+		t.Errorf("expected indexes to drop evicted nodes, got %s", got)
+	}
+}
+
+func TestExecutionTree_StartTimeIndex(t *testing.T) {
+	tree := newExecutionTree(0)
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Executions finish out of start order
+	addTimedNode(tree, "late", "sync", ExecutionStatusSuccess, base.Add(3*time.Second), time.Millisecond, nil)
+	addTimedNode(tree, "early", "sync", ExecutionStatusSuccess, base, 5*time.Second, nil)
+	addTimedNode(tree, "mid", "report", ExecutionStatusSuccess, base.Add(2*time.Second), time.Millisecond, nil)
+	addTimedNode(tree, "tie", "sync", ExecutionStatusFailed, base.Add(2*time.Second), time.Millisecond, nil)
+
+	ids := func(nodes []*ExecutionNode) string {
+		var out []string
+		for _, n := range nodes {
+			out = append(out, n.ID)
+		}
+		return fmt.Sprint(out)
+	}
+
+	if got := ids(tree.byStart); got != "[early mid tie late]" {
+		t.Errorf("expected index ordered by start time, got %s", got)
+	}
+	if got := ids(tree.startedWithin(base.Add(time.Second), base.Add(2*time.Second))); got != "[mid tie]" {
+		t.Errorf("closed range: expected [mid tie], got %s", got)
+	}
+	if got := ids(tree.startedWithin(time.Time{}, base.Add(time.Second))); got != "[early]" {
+		t.Errorf("open start: expected [early], got %s", got)
+	}
+	if got := ids(tree.startedWithin(base.Add(2*time.Second), time.Time{})); got != "[mid tie late]" {
+		t.Errorf("open end: expected [mid tie late], got %s", got)
+	}
+	if n := tree.Query().FlowName("sync").StartedBetween(base.Add(time.Second), time.Time{}).Count(); n != 2 {
+		t.Errorf("expected name and start filters combined to match 2, got %d", n)
+	}
+
+	tree.limit = 3
+	addTimedNode(tree, "next", "sync", ExecutionStatusSuccess, base.Add(4*time.Second), time.Millisecond, nil)
+	if got := ids(tree.byStart); len(tree.byStart) != len(tree.nodes) {
+		t.Errorf("expected evicted nodes dropped from the start index, got %s", got)
+	}
+}
@@ -1,5 +1,11 @@
 package pumped
 
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
 // Executor represents a unit of computation with dependencies
 type Executor[T any] struct {
 	factory func(*ResolveCtx) (T, error)
@@ -34,15 +40,109 @@ func (e *Executor[T]) ResolveAny(s *Scope) (any, error) {
 		executorID: e,
 		cleanups:   []cleanupEntry{},
 	}
-	result, err := e.factory(ctx)
+	result, err := e.runFactory(s, ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	s.registerCleanups(e, ctx.cleanups)
+	s.registerCleanups(e, ctx.takeCleanups())
 	return result, nil
 }
 
+// runFactory calls the factory, converting a panic into a ResolveError with
+// context "panic". Cleanups registered before the panic run immediately,
+// since the value they guard is never cached.
+func (e *Executor[T]) runFactory(s *Scope, ctx *ResolveCtx) (result T, err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		stack := debug.Stack()
+
+		if entries := ctx.takeCleanups(); len(entries) > 0 {
+			s.runCleanups(entries, e, "panic")
+		}
+
+		resolveErr := newResolveError(e, fmt.Errorf("panic in factory: %v", r), "panic")
+		resolveErr.StackTrace = stack
+
+		s.mu.RLock()
+		exts := make([]Extension, len(s.extensions))
+		copy(exts, s.extensions)
+		s.mu.RUnlock()
+
+		// Hook errors are attached to the cause so the error stays a
+		// ResolveError reporting the panic
+		for _, ext := range exts {
+			handler, ok := ext.(ResolvePanicHandler)
+			if !ok {
+				continue
+			}
+			if hookErr := handler.OnResolvePanic(s, e, r, stack); hookErr != nil {
+				resolveErr.Cause = errors.Join(resolveErr.Cause, hookErr)
+			}
+		}
+		err = resolveErr
+
+		var zero T
+		result = zero
+	}()
+
+	return e.factory(ctx)
+}
+
+// resolveIn resolves the executor through the scope cache
+func (e *Executor[T]) resolveIn(s *Scope) (any, error) {
+	return Resolve(s, e)
+}
+
+// cachedResolver is implemented by executors that can resolve through the
+// scope cache without knowing their value type
+type cachedResolver interface {
+	resolveIn(*Scope) (any, error)
+}
+
+// resolveDependency resolves a dependency through the scope cache, falling
+// back to a direct factory call for foreign AnyExecutor implementations
+func resolveDependency(s *Scope, exec AnyExecutor) (any, error) {
+	if r, ok := exec.(cachedResolver); ok {
+		return r.resolveIn(s)
+	}
+	return exec.ResolveAny(s)
+}
+
+// resolveDeclared eagerly resolves a declared dependency. A failed Optional
+// singleton is remembered until it is invalidated, so neither this nor
+// TryGet runs its factory again.
+func resolveDeclared(s *Scope, dep Dependency) (any, error) {
+	exec := dep.GetExecutor()
+	if dep.GetMode() != ModeOptional || LifetimeOf(exec) != Singleton {
+		return resolveDependency(s, exec)
+	}
+	if err := s.optionalFailure(exec); err != nil {
+		return nil, err
+	}
+	val, err := resolveDependency(s, exec)
+	if err != nil {
+		s.rememberFailure(exec, err)
+	}
+	return val, err
+}
+
+func (s *Scope) rememberFailure(exec AnyExecutor, err error) {
+	s.owner(exec).failures.Store(exec, err)
+}
+
+// optionalFailure returns the remembered error of a failed Optional
+// dependency
+func (s *Scope) optionalFailure(exec AnyExecutor) error {
+	if val, ok := s.owner(exec).failures.Load(exec); ok {
+		return val.(error)
+	}
+	return nil
+}
+
 // DependencyMode defines how a dependency behaves
 type DependencyMode string
 
@@ -53,6 +153,10 @@ const (
 	ModeReactive DependencyMode = "reactive"
 	// ModeLazy defers resolution until explicitly requested
 	ModeLazy DependencyMode = "lazy"
+	// ModeOptional resolves eagerly but tolerates failure; the controller
+	// reports absence through TryGet. A failed singleton is not retried
+	// until it is updated or released.
+	ModeOptional DependencyMode = "optional"
 )
 
 // Dependency represents an executor with its resolution mode
@@ -94,6 +198,12 @@ func (e *Executor[T]) Lazy() Dependency {
 	return &dependencyWrapper{executor: e, mode: ModeLazy}
 }
 
+// Optional returns a dependency variant whose failure does not fail the
+// dependent
+func (e *Executor[T]) Optional() Dependency {
+	return &dependencyWrapper{executor: e, mode: ModeOptional}
+}
+
 // ExecutorOption is a modifier for executors
 type ExecutorOption func(AnyExecutor)
 
@@ -11,8 +11,9 @@ func Derive1[T any, D1 any](
 		deps: []Dependency{d1},
 		factory: func(ctx *ResolveCtx) (T, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    ctx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			return factory(ctx, ctrl1)
 		},
@@ -36,12 +37,14 @@ func Derive2[T any, D1 any, D2 any](
 		deps: []Dependency{d1, d2},
 		factory: func(ctx *ResolveCtx) (T, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    ctx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl2 := &Controller[D2]{
-				executor: d2.GetExecutor().(*Executor[D2]),
-				scope:    ctx.scope,
+				executor:  d2.GetExecutor().(*Executor[D2]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			return factory(ctx, ctrl1, ctrl2)
 		},
@@ -66,16 +69,19 @@ func Derive3[T any, D1 any, D2 any, D3 any](
 		deps: []Dependency{d1, d2, d3},
 		factory: func(ctx *ResolveCtx) (T, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    ctx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl2 := &Controller[D2]{
-				executor: d2.GetExecutor().(*Executor[D2]),
-				scope:    ctx.scope,
+				executor:  d2.GetExecutor().(*Executor[D2]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl3 := &Controller[D3]{
-				executor: d3.GetExecutor().(*Executor[D3]),
-				scope:    ctx.scope,
+				executor:  d3.GetExecutor().(*Executor[D3]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			return factory(ctx, ctrl1, ctrl2, ctrl3)
 		},
@@ -101,20 +107,24 @@ func Derive4[T any, D1 any, D2 any, D3 any, D4 any](
 		deps: []Dependency{d1, d2, d3, d4},
 		factory: func(ctx *ResolveCtx) (T, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    ctx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl2 := &Controller[D2]{
-				executor: d2.GetExecutor().(*Executor[D2]),
-				scope:    ctx.scope,
+				executor:  d2.GetExecutor().(*Executor[D2]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl3 := &Controller[D3]{
-				executor: d3.GetExecutor().(*Executor[D3]),
-				scope:    ctx.scope,
+				executor:  d3.GetExecutor().(*Executor[D3]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl4 := &Controller[D4]{
-				executor: d4.GetExecutor().(*Executor[D4]),
-				scope:    ctx.scope,
+				executor:  d4.GetExecutor().(*Executor[D4]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			return factory(ctx, ctrl1, ctrl2, ctrl3, ctrl4)
 		},
@@ -141,24 +151,29 @@ func Derive5[T any, D1 any, D2 any, D3 any, D4 any, D5 any](
 		deps: []Dependency{d1, d2, d3, d4, d5},
 		factory: func(ctx *ResolveCtx) (T, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    ctx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl2 := &Controller[D2]{
-				executor: d2.GetExecutor().(*Executor[D2]),
-				scope:    ctx.scope,
+				executor:  d2.GetExecutor().(*Executor[D2]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl3 := &Controller[D3]{
-				executor: d3.GetExecutor().(*Executor[D3]),
-				scope:    ctx.scope,
+				executor:  d3.GetExecutor().(*Executor[D3]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl4 := &Controller[D4]{
-				executor: d4.GetExecutor().(*Executor[D4]),
-				scope:    ctx.scope,
+				executor:  d4.GetExecutor().(*Executor[D4]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl5 := &Controller[D5]{
-				executor: d5.GetExecutor().(*Executor[D5]),
-				scope:    ctx.scope,
+				executor:  d5.GetExecutor().(*Executor[D5]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			return factory(ctx, ctrl1, ctrl2, ctrl3, ctrl4, ctrl5)
 		},
@@ -186,28 +201,34 @@ func Derive6[T any, D1 any, D2 any, D3 any, D4 any, D5 any, D6 any](
 		deps: []Dependency{d1, d2, d3, d4, d5, d6},
 		factory: func(ctx *ResolveCtx) (T, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    ctx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl2 := &Controller[D2]{
-				executor: d2.GetExecutor().(*Executor[D2]),
-				scope:    ctx.scope,
+				executor:  d2.GetExecutor().(*Executor[D2]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl3 := &Controller[D3]{
-				executor: d3.GetExecutor().(*Executor[D3]),
-				scope:    ctx.scope,
+				executor:  d3.GetExecutor().(*Executor[D3]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl4 := &Controller[D4]{
-				executor: d4.GetExecutor().(*Executor[D4]),
-				scope:    ctx.scope,
+				executor:  d4.GetExecutor().(*Executor[D4]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl5 := &Controller[D5]{
-				executor: d5.GetExecutor().(*Executor[D5]),
-				scope:    ctx.scope,
+				executor:  d5.GetExecutor().(*Executor[D5]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl6 := &Controller[D6]{
-				executor: d6.GetExecutor().(*Executor[D6]),
-				scope:    ctx.scope,
+				executor:  d6.GetExecutor().(*Executor[D6]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			return factory(ctx, ctrl1, ctrl2, ctrl3, ctrl4, ctrl5, ctrl6)
 		},
@@ -236,32 +257,39 @@ func Derive7[T any, D1 any, D2 any, D3 any, D4 any, D5 any, D6 any, D7 any](
 		deps: []Dependency{d1, d2, d3, d4, d5, d6, d7},
 		factory: func(ctx *ResolveCtx) (T, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    ctx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl2 := &Controller[D2]{
-				executor: d2.GetExecutor().(*Executor[D2]),
-				scope:    ctx.scope,
+				executor:  d2.GetExecutor().(*Executor[D2]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl3 := &Controller[D3]{
-				executor: d3.GetExecutor().(*Executor[D3]),
-				scope:    ctx.scope,
+				executor:  d3.GetExecutor().(*Executor[D3]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl4 := &Controller[D4]{
-				executor: d4.GetExecutor().(*Executor[D4]),
-				scope:    ctx.scope,
+				executor:  d4.GetExecutor().(*Executor[D4]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl5 := &Controller[D5]{
-				executor: d5.GetExecutor().(*Executor[D5]),
-				scope:    ctx.scope,
+				executor:  d5.GetExecutor().(*Executor[D5]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl6 := &Controller[D6]{
-				executor: d6.GetExecutor().(*Executor[D6]),
-				scope:    ctx.scope,
+				executor:  d6.GetExecutor().(*Executor[D6]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl7 := &Controller[D7]{
-				executor: d7.GetExecutor().(*Executor[D7]),
-				scope:    ctx.scope,
+				executor:  d7.GetExecutor().(*Executor[D7]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			return factory(ctx, ctrl1, ctrl2, ctrl3, ctrl4, ctrl5, ctrl6, ctrl7)
 		},
@@ -291,36 +319,44 @@ func Derive8[T any, D1 any, D2 any, D3 any, D4 any, D5 any, D6 any, D7 any, D8 a
 		deps: []Dependency{d1, d2, d3, d4, d5, d6, d7, d8},
 		factory: func(ctx *ResolveCtx) (T, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    ctx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl2 := &Controller[D2]{
-				executor: d2.GetExecutor().(*Executor[D2]),
-				scope:    ctx.scope,
+				executor:  d2.GetExecutor().(*Executor[D2]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl3 := &Controller[D3]{
-				executor: d3.GetExecutor().(*Executor[D3]),
-				scope:    ctx.scope,
+				executor:  d3.GetExecutor().(*Executor[D3]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl4 := &Controller[D4]{
-				executor: d4.GetExecutor().(*Executor[D4]),
-				scope:    ctx.scope,
+				executor:  d4.GetExecutor().(*Executor[D4]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl5 := &Controller[D5]{
-				executor: d5.GetExecutor().(*Executor[D5]),
-				scope:    ctx.scope,
+				executor:  d5.GetExecutor().(*Executor[D5]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl6 := &Controller[D6]{
-				executor: d6.GetExecutor().(*Executor[D6]),
-				scope:    ctx.scope,
+				executor:  d6.GetExecutor().(*Executor[D6]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl7 := &Controller[D7]{
-				executor: d7.GetExecutor().(*Executor[D7]),
-				scope:    ctx.scope,
+				executor:  d7.GetExecutor().(*Executor[D7]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl8 := &Controller[D8]{
-				executor: d8.GetExecutor().(*Executor[D8]),
-				scope:    ctx.scope,
+				executor:  d8.GetExecutor().(*Executor[D8]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			return factory(ctx, ctrl1, ctrl2, ctrl3, ctrl4, ctrl5, ctrl6, ctrl7, ctrl8)
 		},
@@ -351,40 +387,49 @@ func Derive9[T any, D1 any, D2 any, D3 any, D4 any, D5 any, D6 any, D7 any, D8 a
 		deps: []Dependency{d1, d2, d3, d4, d5, d6, d7, d8, d9},
 		factory: func(ctx *ResolveCtx) (T, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    ctx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl2 := &Controller[D2]{
-				executor: d2.GetExecutor().(*Executor[D2]),
-				scope:    ctx.scope,
+				executor:  d2.GetExecutor().(*Executor[D2]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl3 := &Controller[D3]{
-				executor: d3.GetExecutor().(*Executor[D3]),
-				scope:    ctx.scope,
+				executor:  d3.GetExecutor().(*Executor[D3]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl4 := &Controller[D4]{
-				executor: d4.GetExecutor().(*Executor[D4]),
-				scope:    ctx.scope,
+				executor:  d4.GetExecutor().(*Executor[D4]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl5 := &Controller[D5]{
-				executor: d5.GetExecutor().(*Executor[D5]),
-				scope:    ctx.scope,
+				executor:  d5.GetExecutor().(*Executor[D5]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl6 := &Controller[D6]{
-				executor: d6.GetExecutor().(*Executor[D6]),
-				scope:    ctx.scope,
+				executor:  d6.GetExecutor().(*Executor[D6]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl7 := &Controller[D7]{
-				executor: d7.GetExecutor().(*Executor[D7]),
-				scope:    ctx.scope,
+				executor:  d7.GetExecutor().(*Executor[D7]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl8 := &Controller[D8]{
-				executor: d8.GetExecutor().(*Executor[D8]),
-				scope:    ctx.scope,
+				executor:  d8.GetExecutor().(*Executor[D8]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			ctrl9 := &Controller[D9]{
-				executor: d9.GetExecutor().(*Executor[D9]),
-				scope:    ctx.scope,
+				executor:  d9.GetExecutor().(*Executor[D9]),
+				scope:     ctx.scope,
+				requester: ctx,
 			}
 			return factory(ctx, ctrl1, ctrl2, ctrl3, ctrl4, ctrl5, ctrl6, ctrl7, ctrl8, ctrl9)
 		},
@@ -2,6 +2,7 @@ package pumped
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -322,7 +323,8 @@ func TestLazyErrorHandling(t *testing.T) {
 			if err == nil {
 				t.Error("expected error from lazy dependency")
 			}
-			if err.Error() != "dependency failed" {
+			var depErr *testError
+			if !errors.As(err, &depErr) || depErr.msg != "dependency failed" {
 				t.Errorf("expected 'dependency failed', got %v", err)
 			}
 			return val, err
@@ -334,7 +336,16 @@ func TestLazyErrorHandling(t *testing.T) {
 		t.Fatal("expected error to surface")
 	}
 
-	if err.Error() != "dependency failed" {
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) || resolveErr.ExecutorID != AnyExecutor(failingExec) {
+		t.Fatalf("expected ResolveError for the failing executor, got %v", err)
+	}
+	if len(resolveErr.Path) != 2 || resolveErr.Path[0] != AnyExecutor(derived) {
+		t.Errorf("expected path derived -> failingExec, got %v", resolveErr.Path)
+	}
+
+	var depErr *testError
+	if !errors.As(err, &depErr) || depErr.msg != "dependency failed" {
 		t.Errorf("expected 'dependency failed', got %v", err)
 	}
 }
@@ -0,0 +1,364 @@
+package pumped
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ExecutionRecord is the serializable form of an execution node. Well-known
+// tags become typed fields; other tags are keyed by their tag name.
+type ExecutionRecord struct {
+	ID         string         `json:"id"`
+	ParentID   string         `json:"parent_id,omitempty"`
+	FlowName   string         `json:"flow_name,omitempty"`
+	Status     string         `json:"status"`
+	StartTime  *time.Time     `json:"start_time,omitempty"`
+	EndTime    *time.Time     `json:"end_time,omitempty"`
+	DurationNs int64          `json:"duration_ns,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	PanicStack string         `json:"panic_stack,omitempty"`
+	Input      any            `json:"input,omitempty"`
+	Output     any            `json:"output,omitempty"`
+	Tags       map[string]any `json:"tags,omitempty"`
+}
+
+// ExecutionEncoder converts execution snapshots into ExecutionRecords.
+// Tag values without a registered codec are kept when they are JSON
+// scalars, errors, times, durations or values encoding/json can marshal,
+// and rendered with fmt otherwise.
+type ExecutionEncoder struct {
+	codecs map[any]func(any) (any, error)
+}
+
+// NewExecutionEncoder creates an encoder with no custom codecs
+func NewExecutionEncoder() *ExecutionEncoder {
+	return &ExecutionEncoder{
+		codecs: make(map[any]func(any) (any, error)),
+	}
+}
+
+// RegisterTagCodec registers how values stored under tag are encoded.
+// Codecs are looked up by tag only: register one for Input() or Output() to
+// control the record's Input and Output fields.
+func RegisterTagCodec[T any](enc *ExecutionEncoder, tag Tag[T], encode func(T) (any, error)) {
+	enc.codecs[tag] = func(val any) (any, error) {
+		typed, err := SafeTypeAssertion[T](val)
+		if err != nil {
+			return nil, fmt.Errorf("encoding tag %q: %w", tag.key, err)
+		}
+		return encode(typed)
+	}
+}
+
+// Encode converts a snapshot into a record
+func (enc *ExecutionEncoder) Encode(snap ExecutionSnapshot) (ExecutionRecord, error) {
+	rec := ExecutionRecord{
+		ID:       snap.ID,
+		ParentID: snap.ParentID,
+		FlowName: snap.FlowName,
+		Status:   snap.Status.String(),
+	}
+	if !snap.StartTime.IsZero() {
+		start := snap.StartTime
+		rec.StartTime = &start
+	}
+	if !snap.EndTime.IsZero() {
+		end := snap.EndTime
+		rec.EndTime = &end
+	}
+	rec.DurationNs = int64(snap.Duration)
+	if snap.Err != nil {
+		rec.Error = snap.Err.Error()
+	}
+
+	for key, val := range snap.Tags {
+		switch key {
+		case flowNameTag, statusTag, startTimeTag, endTimeTag, errorTag:
+			continue
+		case panicStackTag:
+			if stack, ok := val.([]byte); ok {
+				rec.PanicStack = string(stack)
+			}
+			continue
+		}
+
+		encoded, err := enc.encodeValue(key, val)
+		if err != nil {
+			return rec, err
+		}
+
+		switch key {
+		case inputTag:
+			rec.Input = encoded
+		case outputTag:
+			rec.Output = encoded
+		default:
+			name, ok := tagName(key)
+			if !ok {
+				continue
+			}
+			if rec.Tags == nil {
+				rec.Tags = make(map[string]any)
+			}
+			rec.Tags[name] = encoded
+		}
+	}
+
+	return rec, nil
+}
+
+func (enc *ExecutionEncoder) encodeValue(key any, val any) (any, error) {
+	if codec, ok := enc.codecs[key]; ok {
+		return codec(val)
+	}
+
+	switch v := val.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v, nil
+	case error:
+		return v.Error(), nil
+	case time.Time:
+		return v, nil
+	case time.Duration:
+		return v.String(), nil
+	case []byte:
+		return string(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	if raw, err := json.Marshal(val); err == nil {
+		return json.RawMessage(raw), nil
+	}
+	return fmt.Sprintf("%v", val), nil
+}
+
+func tagName(key any) (string, bool) {
+	named, ok := key.(interface{ Key() string })
+	if !ok {
+		return "", false
+	}
+	return named.Key(), true
+}
+
+// WriteNDJSON writes one JSON record per line
+func (enc *ExecutionEncoder) WriteNDJSON(w io.Writer, snaps []ExecutionSnapshot) error {
+	encoder := json.NewEncoder(w)
+	for _, snap := range snaps {
+		rec, err := enc.Encode(snap)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(rec); err != nil {
+			return fmt.Errorf("writing execution %s: %w", snap.ID, err)
+		}
+	}
+	return nil
+}
+
+// WriteOTLP writes the snapshots as an OTLP-JSON trace export request
+// (ExportTraceServiceRequest). Each root execution becomes a trace and each
+// node a span; IDs are derived deterministically from execution IDs.
+func (enc *ExecutionEncoder) WriteOTLP(w io.Writer, serviceName string, snaps []ExecutionSnapshot) error {
+	parents := make(map[string]string, len(snaps))
+	for _, snap := range snaps {
+		parents[snap.ID] = snap.ParentID
+	}
+
+	spans := make([]otlpSpan, 0, len(snaps))
+	for _, snap := range snaps {
+		rec, err := enc.Encode(snap)
+		if err != nil {
+			return err
+		}
+		spans = append(spans, toOTLPSpan(snap, rec, traceRoot(snap.ID, parents)))
+	}
+
+	req := otlpExport{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{{Key: "service.name", Value: otlpValue{StringValue: &serviceName}}},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/pumped-fn/pumped-go"},
+				Spans: spans,
+			}},
+		}},
+	}
+
+	if err := json.NewEncoder(w).Encode(req); err != nil {
+		return fmt.Errorf("writing OTLP export: %w", err)
+	}
+	return nil
+}
+
+// traceRoot walks up known parents to find the ID the trace is derived
+// from. A parent missing from the export still anchors the trace.
+func traceRoot(id string, parents map[string]string) string {
+	for range parents {
+		parent := parents[id]
+		if parent == "" {
+			return id
+		}
+		if _, known := parents[parent]; !known {
+			return parent
+		}
+		id = parent
+	}
+	return id
+}
+
+func otlpID(id string, size int) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:size])
+}
+
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusOK         = 1
+	otlpStatusError      = 2
+)
+
+type otlpExport struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func toOTLPSpan(snap ExecutionSnapshot, rec ExecutionRecord, root string) otlpSpan {
+	name := rec.FlowName
+	if name == "" {
+		name = "flow"
+	}
+
+	span := otlpSpan{
+		TraceID:           otlpID(root, 16),
+		SpanID:            otlpID(snap.ID, 8),
+		Name:              name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: unixNano(snap.StartTime),
+		EndTimeUnixNano:   unixNano(snap.EndTime),
+	}
+	if snap.ParentID != "" {
+		span.ParentSpanID = otlpID(snap.ParentID, 8)
+	}
+
+	switch snap.Status {
+	case ExecutionStatusSuccess:
+		span.Status = otlpStatus{Code: otlpStatusOK}
+	case ExecutionStatusFailed, ExecutionStatusCancelled, ExecutionStatusRejected:
+		span.Status = otlpStatus{Code: otlpStatusError, Message: rec.Error}
+	}
+
+	span.Attributes = append(span.Attributes,
+		otlpAttr("pumped.execution.id", rec.ID),
+		otlpAttr("pumped.execution.status", rec.Status),
+	)
+	if rec.PanicStack != "" {
+		span.Attributes = append(span.Attributes, otlpAttr("exception.stacktrace", rec.PanicStack))
+	}
+	if rec.Input != nil {
+		span.Attributes = append(span.Attributes, otlpAttr(inputTag.key, rec.Input))
+	}
+	if rec.Output != nil {
+		span.Attributes = append(span.Attributes, otlpAttr(outputTag.key, rec.Output))
+	}
+	for key, val := range rec.Tags {
+		span.Attributes = append(span.Attributes, otlpAttr(key, val))
+	}
+	sortAttributes(span.Attributes)
+
+	return span
+}
+
+func unixNano(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func otlpAttr(key string, val any) otlpKeyValue {
+	var v otlpValue
+	switch x := val.(type) {
+	case string:
+		v.StringValue = &x
+	case bool:
+		v.BoolValue = &x
+	case int:
+		s := strconv.FormatInt(int64(x), 10)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(x, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &x
+	case time.Time:
+		s := x.Format(time.RFC3339Nano)
+		v.StringValue = &s
+	case json.RawMessage:
+		s := string(x)
+		v.StringValue = &s
+	default:
+		s := fmt.Sprintf("%v", x)
+		v.StringValue = &s
+	}
+	return otlpKeyValue{Key: key, Value: v}
+}
+
+func sortAttributes(attrs []otlpKeyValue) {
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+}
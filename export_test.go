@@ -0,0 +1,188 @@
+package pumped
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type exportTestUser struct {
+	ID   string
+	Name string
+}
+
+func TestExecutionEncoder_NDJSON(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	userTag := NewTag[exportTestUser]("test.user")
+	attemptTag := NewTag[int]("test.attempt")
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	child := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 0, errors.New("child failed")
+	}, WithFlowTag(FlowName(), "child"))
+
+	parent := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		execCtx.Set(Input(), map[string]int{"items": 3})
+		SetValue(execCtx, userTag, exportTestUser{ID: "u1", Name: "Ada"})
+		SetValue(execCtx, attemptTag, 2)
+		_, _, _ = Exec1(execCtx, child)
+		return "done", nil
+	}, WithFlowTag(FlowName(), "parent"))
+
+	if _, _, err := Exec(scope, context.Background(), parent); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	enc := NewExecutionEncoder()
+	RegisterTagCodec(enc, userTag, func(u exportTestUser) (any, error) {
+		return u.ID, nil
+	})
+
+	var buf bytes.Buffer
+	if err := enc.WriteNDJSON(&buf, scope.GetExecutionTree().Query().Run()); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	records := map[string]map[string]any{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var rec map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		records[rec["flow_name"].(string)] = rec
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	p := records["parent"]
+	if p["status"] != "success" || p["output"] != "done" {
+		t.Errorf("unexpected parent record: %v", p)
+	}
+	if input, ok := p["input"].(map[string]any); !ok || input["items"] != float64(3) {
+		t.Errorf("expected input to be encoded as JSON, got %v", p["input"])
+	}
+	tags := p["tags"].(map[string]any)
+	if tags["test.user"] != "u1" {
+		t.Errorf("expected registered codec for user tag, got %v", tags["test.user"])
+	}
+	if tags["test.attempt"] != float64(2) {
+		t.Errorf("expected attempt tag by name, got %v", tags["test.attempt"])
+	}
+
+	c := records["child"]
+	if c["status"] != "failed" || c["error"] != "child failed" || c["parent_id"] != p["id"] {
+		t.Errorf("unexpected child record: %v", c)
+	}
+	if _, ok := c["start_time"]; !ok {
+		t.Error("expected start_time on child record")
+	}
+}
+
+func TestExecutionEncoder_OTLP(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	child := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		panic("kaboom")
+	}, WithFlowTag(FlowName(), "child"))
+
+	parent := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		_, _, err := Exec1(execCtx, child)
+		return 0, err
+	}, WithFlowTag(FlowName(), "parent"))
+
+	_, _, _ = Exec(scope, context.Background(), parent)
+
+	var buf bytes.Buffer
+	enc := NewExecutionEncoder()
+	if err := enc.WriteOTLP(&buf, "orders", scope.GetExecutionTree().Query().Run()); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	var export otlpExport
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatalf("invalid OTLP JSON: %v", err)
+	}
+
+	rs := export.ResourceSpans[0]
+	if *rs.Resource.Attributes[0].Value.StringValue != "orders" {
+		t.Errorf("expected service.name orders, got %v", rs.Resource.Attributes)
+	}
+
+	spans := map[string]otlpSpan{}
+	for _, span := range rs.ScopeSpans[0].Spans {
+		spans[span.Name] = span
+	}
+
+	p, c := spans["parent"], spans["child"]
+	if len(p.TraceID) != 32 || len(p.SpanID) != 16 {
+		t.Errorf("unexpected ID lengths: trace %q span %q", p.TraceID, p.SpanID)
+	}
+	if c.TraceID != p.TraceID || c.ParentSpanID != p.SpanID {
+		t.Errorf("child span not linked to parent: %+v / %+v", c, p)
+	}
+	if c.Status.Code != otlpStatusError || !strings.Contains(c.Status.Message, "kaboom") {
+		t.Errorf("expected error status on panicking child, got %+v", c.Status)
+	}
+
+	hasStack := false
+	for _, attr := range c.Attributes {
+		if attr.Key == "exception.stacktrace" {
+			hasStack = true
+		}
+	}
+	if !hasStack {
+		t.Error("expected panic stack attribute on child span")
+	}
+}
+
+func TestExecutionEncoder_InputOutputCodecs(t *testing.T) {
+	userTag := NewTag[exportTestUser]("test.user")
+	user := exportTestUser{ID: "u1", Name: "Ada"}
+
+	enc := NewExecutionEncoder()
+	RegisterTagCodec(enc, userTag, func(u exportTestUser) (any, error) {
+		return u.ID, nil
+	})
+	RegisterTagCodec(enc, Output(), func(out any) (any, error) {
+		return "redacted", nil
+	})
+
+	rec, err := enc.Encode(ExecutionSnapshot{
+		ID: "exec-1",
+		Tags: map[any]any{
+			userTag:   user,
+			inputTag:  user,
+			outputTag: "secret",
+		},
+	})
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	if rec.Tags["test.user"] != "u1" {
+		t.Errorf("expected tag codec to apply, got %v", rec.Tags["test.user"])
+	}
+	if input, _ := json.Marshal(rec.Input); !strings.Contains(string(input), "Ada") {
+		t.Errorf("expected Input to ignore the user tag codec, got %s", input)
+	}
+	if rec.Output != "redacted" {
+		t.Errorf("expected Output codec to apply, got %v", rec.Output)
+	}
+}
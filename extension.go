@@ -13,7 +13,7 @@ type Extension interface {
 	// Init is called when the extension is registered to a scope
 	Init(scope *Scope) error
 
-	// Wrap intercepts operations (resolve, update)
+	// Wrap intercepts operations (resolve, update, exec)
 	Wrap(ctx context.Context, next func() (any, error), op *Operation) (any, error)
 
 	// OnError handles errors during resolution
@@ -34,9 +34,10 @@ type Extension interface {
 
 // CleanupError contains information about a cleanup failure
 type CleanupError struct {
-	ExecutorID AnyExecutor
-	Err        error
-	Context    string // "reactive" or "dispose"
+	ExecutorID  AnyExecutor
+	ExecutionID string // set for cleanups owned by a flow execution
+	Err         error
+	Context     string // "reactive", "dispose", "flow", "queue" or "panic"
 }
 
 // BaseExtension provides default implementations for Extension methods
@@ -93,6 +94,26 @@ type Operation struct {
 	Kind     OperationKind
 	Executor AnyExecutor
 	Scope    *Scope
+
+	// ExecutionCtx and Flow are set for OpExec
+	ExecutionCtx *ExecutionCtx
+	Flow         AnyFlow
+}
+
+// ResolvePanicHandler is implemented by extensions that want to observe
+// panicking executor factories. The panic is returned from Resolve as a
+// ResolveError with context "panic"; errors returned by OnResolvePanic are
+// joined into its Cause.
+type ResolvePanicHandler interface {
+	OnResolvePanic(scope *Scope, exec AnyExecutor, recovered any, stack []byte) error
+}
+
+// ForkableExtension is implemented by extensions with per-scope state.
+// Scope.Fork registers the instance returned by Fork on the new scope;
+// other extensions are shared with the fork.
+type ForkableExtension interface {
+	Extension
+	Fork() Extension
 }
 
 // OperationKind represents the type of operation
@@ -103,4 +124,10 @@ const (
 	OpResolve OperationKind = "resolve"
 	// OpUpdate indicates an executor update
 	OpUpdate OperationKind = "update"
+	// OpExec indicates a flow execution. Wrap may call next more than once
+	// to retry, skip it to substitute a result, or swap the execution's
+	// context via ExecutionCtx.SetContext before calling it.
+	OpExec OperationKind = "exec"
+	// OpPreset indicates a preset being set or cleared at runtime
+	OpPreset OperationKind = "preset"
 )
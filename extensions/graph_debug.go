@@ -2,6 +2,7 @@ package extensions
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -207,6 +208,7 @@ func (e *GraphDebugExtension) formatDependencyGraph(scope *pumped.Scope, failedE
 
 	if len(graph) == 0 {
 		sb.WriteString("\n(empty - no reactive dependencies tracked)")
+		sb.WriteString(e.formatFallbacks(scope))
 		return sb.String()
 	}
 
@@ -302,6 +304,8 @@ func (e *GraphDebugExtension) formatDependencyGraph(scope *pumped.Scope, failedE
 		}
 	}
 
+	sb.WriteString(e.formatFallbacks(scope))
+
 	// Show error details for the failed executor
 	if failedErr != nil {
 		sb.WriteString("\nError Details:\n")
@@ -312,10 +316,60 @@ func (e *GraphDebugExtension) formatDependencyGraph(scope *pumped.Scope, failedE
 	return sb.String()
 }
 
+// formatFallbacks lists which branch each tracked OrElse executor took
+func (e *GraphDebugExtension) formatFallbacks(scope *pumped.Scope) string {
+	var fallbacks []string
+	seen := make(map[pumped.AnyExecutor]bool)
+	for _, tracked := range []map[pumped.AnyExecutor]bool{e.resolvedExecutors, e.failedKeys()} {
+		for exec := range tracked {
+			if seen[exec] {
+				continue
+			}
+			seen[exec] = true
+			branch, ok := scope.FallbackBranch(exec)
+			if !ok {
+				continue
+			}
+			if branch.UsedFallback {
+				cause := branch.PrimaryErr
+				var resolveErr *pumped.ResolveError
+				if errors.As(cause, &resolveErr) {
+					cause = resolveErr.Cause
+				}
+				fallbacks = append(fallbacks, fmt.Sprintf("  %s → fallback (primary error: %v)\n", e.getExecutorName(exec), cause))
+			} else {
+				fallbacks = append(fallbacks, fmt.Sprintf("  %s → primary\n", e.getExecutorName(exec)))
+			}
+		}
+	}
+	if len(fallbacks) == 0 {
+		return ""
+	}
+
+	sort.Strings(fallbacks)
+	var sb strings.Builder
+	sb.WriteString("\nFallbacks:\n")
+	for _, line := range fallbacks {
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+func (e *GraphDebugExtension) failedKeys() map[pumped.AnyExecutor]bool {
+	keys := make(map[pumped.AnyExecutor]bool, len(e.failedExecutors))
+	for exec := range e.failedExecutors {
+		keys[exec] = true
+	}
+	return keys
+}
+
 func (e *GraphDebugExtension) getExecutorName(exec pumped.AnyExecutor) string {
 	if name, ok := e.nameTag.Get(exec); ok {
 		return name
 	}
+	if member, ok := pumped.FamilyMember().Get(exec); ok {
+		return fmt.Sprintf("%s[%v]", member.Family, member.Key)
+	}
 	return fmt.Sprintf("Executor_%p", exec)
 }
 
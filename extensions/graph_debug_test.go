@@ -1152,3 +1152,36 @@ func TestGraphDebugExtension_NestedWithBranching(t *testing.T) {
 	t.Logf("           \\    |    /   /")
 	t.Logf("            Aggregator (FAILED)")
 }
+
+func TestGraphDebugExtension_ShowsFallbackBranch(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewHumanHandler(&buf, slog.LevelError)
+
+	scope := pumped.NewScope(
+		pumped.WithExtension(NewGraphDebugExtension(handler)),
+	)
+	defer scope.Dispose()
+
+	nameTag := pumped.NewTag[string]("executor.name")
+
+	redis := pumped.Provide(func(ctx *pumped.ResolveCtx) (string, error) {
+		return "", fmt.Errorf("redis down")
+	})
+	memory := pumped.Provide(func(ctx *pumped.ResolveCtx) (string, error) {
+		return "memory", nil
+	})
+	cache := redis.OrElse(memory, pumped.WithTag(nameTag, "Cache"))
+
+	handlerExec := pumped.Derive1(cache, func(ctx *pumped.ResolveCtx, c *pumped.Controller[string]) (string, error) {
+		return "", fmt.Errorf("handler failed")
+	}, pumped.WithTag(nameTag, "Handler"))
+
+	if _, err := pumped.Resolve(scope, handlerExec); err == nil {
+		t.Fatal("Expected error but got nil")
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "Cache → fallback (primary error: redis down)") {
+		t.Errorf("Expected fallback branch in output, got:\n%s", output)
+	}
+}
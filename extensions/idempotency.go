@@ -0,0 +1,353 @@
+package extensions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+// ErrIdempotencyInProgress is returned to a duplicate execution that gave
+// up waiting for the first one to finish
+var ErrIdempotencyInProgress = errors.New("idempotent execution in progress")
+
+// IdempotencyState is the state of an idempotency record
+type IdempotencyState int
+
+const (
+	IdempotencyInProgress IdempotencyState = iota
+	IdempotencyCompleted
+)
+
+// IdempotencyRecord is the stored outcome of the first execution for a key
+type IdempotencyRecord struct {
+	Key       string
+	State     IdempotencyState
+	Result    any
+	Err       error
+	ExpiresAt time.Time
+}
+
+// IdempotencyStore persists execution outcomes by idempotency key. Stores
+// shared between processes must make Reserve atomic.
+type IdempotencyStore interface {
+	// Reserve claims key for a new execution. When the key already has a
+	// live record it returns that record and false.
+	Reserve(ctx context.Context, key string, ttl time.Duration) (IdempotencyRecord, bool, error)
+	// Complete stores the outcome of the execution that reserved key
+	Complete(ctx context.Context, key string, result any, err error, ttl time.Duration) error
+	// Release drops a reservation whose execution did not finish, so a
+	// later duplicate runs again
+	Release(ctx context.Context, key string) error
+}
+
+type memoryIdempotencyStore struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+// NewMemoryIdempotencyStore returns an in-process IdempotencyStore. now
+// may be nil to use time.Now.
+func NewMemoryIdempotencyStore(now func() time.Time) IdempotencyStore {
+	if now == nil {
+		now = time.Now
+	}
+	return &memoryIdempotencyStore{
+		now:     now,
+		records: make(map[string]IdempotencyRecord),
+	}
+}
+
+func (m *memoryIdempotencyStore) Reserve(ctx context.Context, key string, ttl time.Duration) (IdempotencyRecord, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if rec, ok := m.records[key]; ok && (rec.ExpiresAt.IsZero() || now.Before(rec.ExpiresAt)) {
+		return rec, false, nil
+	}
+
+	rec := IdempotencyRecord{Key: key, State: IdempotencyInProgress}
+	if ttl > 0 {
+		rec.ExpiresAt = now.Add(ttl)
+	}
+	m.records[key] = rec
+	return rec, true, nil
+}
+
+func (m *memoryIdempotencyStore) Complete(ctx context.Context, key string, result any, err error, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec := IdempotencyRecord{
+		Key:    key,
+		State:  IdempotencyCompleted,
+		Result: result,
+		Err:    err,
+	}
+	if ttl > 0 {
+		rec.ExpiresAt = m.now().Add(ttl)
+	}
+	m.records[key] = rec
+	return nil
+}
+
+func (m *memoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rec, ok := m.records[key]; ok && rec.State == IdempotencyInProgress {
+		delete(m.records, key)
+	}
+	return nil
+}
+
+// IdempotencyConfig declares a flow idempotent
+type IdempotencyConfig struct {
+	// Key derives the idempotency key; returning false runs the execution
+	// without protection
+	Key func(*pumped.ExecutionCtx) (string, bool)
+	// TTL is how long outcomes are kept; it also bounds how long a
+	// reservation survives an owner that never completes
+	TTL time.Duration
+	// Name prefixes keys (default the flow name)
+	Name string
+	// PollInterval is how often a duplicate checks the store when the
+	// first execution runs in another process (default 50ms)
+	PollInterval time.Duration
+	// MaxWait bounds how long a duplicate waits for the first execution;
+	// zero waits as long as the context allows
+	MaxWait time.Duration
+}
+
+var (
+	idempotencyTag = pumped.NewTag[IdempotencyConfig]("idempotency.config")
+	replayedTag    = pumped.NewTag[bool]("idempotency.replayed")
+)
+
+// Idempotent is the tag that declares a flow idempotent
+// (pumped.WithFlowTag)
+func Idempotent() pumped.Tag[IdempotencyConfig] { return idempotencyTag }
+
+// Replayed is set on executions that returned a stored outcome
+func Replayed() pumped.Tag[bool] { return replayedTag }
+
+// IdempotencyExtension gives flows at-most-once semantics per key. The
+// first execution for a key runs and its result or error is stored;
+// concurrent duplicates wait for it and later duplicates return the stored
+// outcome without running the factory.
+//
+// Usage:
+//
+//	charge := pumped.Flow1(gateway, chargeHandler,
+//	    pumped.WithFlowTag(pumped.FlowName(), "charge"),
+//	    pumped.WithFlowTag(extensions.Idempotent(), extensions.IdempotencyConfig{
+//	        Key: extensions.KeyFromInput(),
+//	        TTL: 24 * time.Hour,
+//	    }),
+//	)
+//
+//	ext := extensions.NewIdempotencyExtension(extensions.NewMemoryIdempotencyStore(nil))
+//	scope := pumped.NewScope(pumped.WithExtension(ext))
+//
+// Executions cancelled before their factory returns release their key. A
+// factory abandoned on cancellation keeps the key reserved until it
+// returns; its late outcome is then stored and replayed to duplicates.
+type IdempotencyExtension struct {
+	pumped.BaseExtension
+	store IdempotencyStore
+
+	mu      sync.Mutex
+	running map[string]chan struct{}
+	keys    map[string]*idempotencyKeyLock
+}
+
+// idempotencyKeyLock serializes Reserve calls for one key
+type idempotencyKeyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewIdempotencyExtension creates an idempotency extension backed by store
+func NewIdempotencyExtension(store IdempotencyStore) *IdempotencyExtension {
+	return &IdempotencyExtension{
+		BaseExtension: pumped.NewBaseExtension("idempotency"),
+		store:         store,
+		running:       make(map[string]chan struct{}),
+		keys:          make(map[string]*idempotencyKeyLock),
+	}
+}
+
+// Fork returns an instance for a forked scope. A store created by
+// NewMemoryIdempotencyStore is replaced by an empty one, so forks do not
+// replay each other's outcomes; other stores are shared.
+func (e *IdempotencyExtension) Fork() pumped.Extension {
+	store := e.store
+	if mem, ok := store.(*memoryIdempotencyStore); ok {
+		store = NewMemoryIdempotencyStore(mem.now)
+	}
+	return NewIdempotencyExtension(store)
+}
+
+// Wrap runs the first execution for a key and replays its outcome for
+// duplicates
+func (e *IdempotencyExtension) Wrap(ctx context.Context, next func() (any, error), op *pumped.Operation) (any, error) {
+	if op.Kind != pumped.OpExec || op.Flow == nil || op.ExecutionCtx == nil {
+		return next()
+	}
+	val, ok := op.Flow.GetTag(idempotencyTag)
+	if !ok {
+		return next()
+	}
+	cfg := val.(IdempotencyConfig)
+	if cfg.Key == nil {
+		return next()
+	}
+	key, ok := cfg.Key(op.ExecutionCtx)
+	if !ok {
+		return next()
+	}
+	name := cfg.Name
+	if name == "" {
+		name = targetName(op.Flow)
+	}
+	key = name + ":" + key
+
+	poll := cfg.PollInterval
+	if poll <= 0 {
+		poll = 50 * time.Millisecond
+	}
+	var deadline <-chan time.Time
+	if cfg.MaxWait > 0 {
+		timer := time.NewTimer(cfg.MaxWait)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		// Hold the key's lock across Reserve so a local duplicate either
+		// sees the running channel or the completed record
+		unlock := e.lockKey(key)
+		rec, reserved, err := e.store.Reserve(ctx, key, cfg.TTL)
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		e.mu.Lock()
+		if reserved {
+			done := make(chan struct{})
+			e.running[key] = done
+			e.mu.Unlock()
+			unlock()
+			return e.runFirst(ctx, op.ExecutionCtx, key, cfg, next, done)
+		}
+		local := e.running[key]
+		e.mu.Unlock()
+		unlock()
+
+		if rec.State == IdempotencyCompleted {
+			op.ExecutionCtx.Set(replayedTag, true)
+			return rec.Result, rec.Err
+		}
+
+		// Without a local owner the first execution runs elsewhere; poll
+		var pollTimer *time.Timer
+		var wait <-chan time.Time
+		if local == nil {
+			pollTimer = time.NewTimer(poll)
+			wait = pollTimer.C
+		}
+
+		select {
+		case <-local:
+		case <-wait:
+		case <-deadline:
+			return nil, ErrIdempotencyInProgress
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if pollTimer != nil {
+			pollTimer.Stop()
+		}
+	}
+}
+
+func (e *IdempotencyExtension) runFirst(ctx context.Context, execCtx *pumped.ExecutionCtx, key string, cfg IdempotencyConfig, next func() (any, error), done chan struct{}) (any, error) {
+	// Use a fresh context: the outcome must be stored even if ctx ended
+	storeCtx := context.WithoutCancel(ctx)
+
+	returned := false
+	defer func() {
+		if !returned {
+			// next panicked; release the key so duplicates run again
+			e.store.Release(storeCtx, key)
+			e.finish(key, done)
+		}
+	}()
+	result, err := next()
+	returned = true
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		if abandoned, _ := execCtx.Get(pumped.Abandoned()); abandoned == true {
+			// The factory is still running and may yet perform its side
+			// effect, so the key stays reserved and duplicates keep waiting
+			// until its late outcome is stored. If storing fails the
+			// reservation lasts until TTL.
+			execCtx.OnLateResult(func(value any, lateErr error) {
+				e.store.Complete(storeCtx, key, value, lateErr, cfg.TTL)
+				e.finish(key, done)
+			})
+			return result, err
+		}
+
+		defer e.finish(key, done)
+		if relErr := e.store.Release(storeCtx, key); relErr != nil {
+			return result, errors.Join(err, relErr)
+		}
+		return result, err
+	}
+
+	defer e.finish(key, done)
+	if storeErr := e.store.Complete(storeCtx, key, result, err, cfg.TTL); storeErr != nil {
+		return result, errors.Join(err, storeErr)
+	}
+	return result, err
+}
+
+// lockKey takes the lock for key and returns its unlock function
+func (e *IdempotencyExtension) lockKey(key string) func() {
+	e.mu.Lock()
+	l := e.keys[key]
+	if l == nil {
+		l = &idempotencyKeyLock{}
+		e.keys[key] = l
+	}
+	l.refs++
+	e.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		e.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(e.keys, key)
+		}
+		e.mu.Unlock()
+	}
+}
+
+// finish wakes local duplicates waiting on key. The key may have been
+// reserved again after its reservation expired, so only done's own entry
+// is removed.
+func (e *IdempotencyExtension) finish(key string, done chan struct{}) {
+	e.mu.Lock()
+	if e.running[key] == done {
+		delete(e.running, key)
+	}
+	e.mu.Unlock()
+	close(done)
+}
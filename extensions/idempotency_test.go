@@ -0,0 +1,325 @@
+package extensions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+func idempotentFlow(calls *atomic.Int32, fn func() (string, error)) *pumped.Flow[string] {
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	return pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (string, error) {
+		calls.Add(1)
+		return fn()
+	},
+		pumped.WithFlowTag(pumped.FlowName(), "charge"),
+		pumped.WithFlowTag(Idempotent(), IdempotencyConfig{
+			Key: KeyFromInput(),
+			TTL: time.Hour,
+		}),
+	)
+}
+
+func execWithInput(scope *pumped.Scope, flow *pumped.Flow[string], input string) (string, *pumped.ExecutionCtx, error) {
+	return pumped.Exec(scope, context.Background(), flow, pumped.WithExecTag(pumped.Input(), any(input)))
+}
+
+func TestIdempotencyReplaysResult(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewIdempotencyExtension(NewMemoryIdempotencyStore(nil))))
+	defer scope.Dispose()
+
+	var calls atomic.Int32
+	flow := idempotentFlow(&calls, func() (string, error) {
+		return "charged", nil
+	})
+
+	if res, _, err := execWithInput(scope, flow, "order-1"); err != nil || res != "charged" {
+		t.Fatalf("expected charged, got %q, %v", res, err)
+	}
+
+	res, execCtx, err := execWithInput(scope, flow, "order-1")
+	if err != nil || res != "charged" {
+		t.Fatalf("expected replayed result, got %q, %v", res, err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected factory to run once, got %d", calls.Load())
+	}
+	if replayed, _ := execCtx.Get(Replayed()); replayed != true {
+		t.Error("expected duplicate to be marked replayed")
+	}
+
+	execWithInput(scope, flow, "order-2")
+	if calls.Load() != 2 {
+		t.Errorf("expected a new key to run, got %d calls", calls.Load())
+	}
+}
+
+func TestIdempotencyReplaysError(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewIdempotencyExtension(NewMemoryIdempotencyStore(nil))))
+	defer scope.Dispose()
+
+	declined := errors.New("card declined")
+	var calls atomic.Int32
+	flow := idempotentFlow(&calls, func() (string, error) {
+		return "", declined
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := execWithInput(scope, flow, "order-1"); !errors.Is(err, declined) {
+			t.Fatalf("attempt %d: expected stored error, got %v", i, err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected factory to run once, got %d", calls.Load())
+	}
+}
+
+func TestIdempotencyConcurrentDuplicatesWait(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewIdempotencyExtension(NewMemoryIdempotencyStore(nil))))
+	defer scope.Dispose()
+
+	gate := make(chan struct{})
+	var calls atomic.Int32
+	flow := idempotentFlow(&calls, func() (string, error) {
+		<-gate
+		return "charged", nil
+	})
+
+	var wg sync.WaitGroup
+	results := make(chan string, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, _, err := execWithInput(scope, flow, "order-1")
+			if err != nil {
+				t.Errorf("Exec failed: %v", err)
+			}
+			results <- res
+		}()
+	}
+
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(gate)
+	wg.Wait()
+	close(results)
+
+	for res := range results {
+		if res != "charged" {
+			t.Errorf("expected charged, got %q", res)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected a single execution, got %d", calls.Load())
+	}
+}
+
+func TestIdempotencyExpiryAndRelease(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	store := NewMemoryIdempotencyStore(clock.Now)
+	scope := pumped.NewScope(pumped.WithExtension(NewIdempotencyExtension(store)))
+	defer scope.Dispose()
+
+	var calls atomic.Int32
+	flow := idempotentFlow(&calls, func() (string, error) {
+		return "charged", nil
+	})
+
+	execWithInput(scope, flow, "order-1")
+	clock.Advance(time.Hour)
+	execWithInput(scope, flow, "order-1")
+	if calls.Load() != 2 {
+		t.Errorf("expected expired outcome to run again, got %d calls", calls.Load())
+	}
+
+	ctx := context.Background()
+	if _, reserved, _ := store.Reserve(ctx, "charge:\"order-9\"", time.Hour); !reserved {
+		t.Fatal("expected reservation")
+	}
+	if err := store.Release(ctx, "charge:\"order-9\""); err != nil {
+		t.Fatal(err)
+	}
+	execWithInput(scope, flow, "order-9")
+	if calls.Load() != 3 {
+		t.Errorf("expected released key to run, got %d calls", calls.Load())
+	}
+}
+
+func TestIdempotencyWaitsForOtherOwner(t *testing.T) {
+	store := NewMemoryIdempotencyStore(nil)
+	scope := pumped.NewScope(pumped.WithExtension(NewIdempotencyExtension(store)))
+	defer scope.Dispose()
+
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	flow := pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (string, error) {
+		return "local", nil
+	},
+		pumped.WithFlowTag(pumped.FlowName(), "charge"),
+		pumped.WithFlowTag(Idempotent(), IdempotencyConfig{
+			Key:          KeyFromInput(),
+			PollInterval: time.Millisecond,
+			MaxWait:      20 * time.Millisecond,
+		}),
+	)
+
+	// Another process holds the key
+	ctx := context.Background()
+	store.Reserve(ctx, "charge:\"order-1\"", 0)
+
+	if _, _, err := execWithInput(scope, flow, "order-1"); !errors.Is(err, ErrIdempotencyInProgress) {
+		t.Fatalf("expected ErrIdempotencyInProgress, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		store.Complete(ctx, "charge:\"order-1\"", "remote", nil, 0)
+	}()
+	if res, _, err := execWithInput(scope, flow, "order-1"); err != nil || res != "remote" {
+		t.Errorf("expected remote outcome, got %q, %v", res, err)
+	}
+}
+
+func TestIdempotencyKeepsKeyOfAbandonedExecution(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewIdempotencyExtension(NewMemoryIdempotencyStore(nil))))
+	defer scope.Dispose()
+
+	started := make(chan struct{})
+	gate := make(chan struct{})
+	var calls atomic.Int32
+	flow := idempotentFlow(&calls, func() (string, error) {
+		if calls.Load() == 1 {
+			close(started)
+		}
+		<-gate
+		return "charged", nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, _, err := pumped.Exec(scope, ctx, flow, pumped.WithExecTag(pumped.Input(), any("order-1")))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+
+	duplicate := make(chan string, 1)
+	go func() {
+		res, _, err := execWithInput(scope, flow, "order-1")
+		if err != nil {
+			t.Errorf("duplicate failed: %v", err)
+		}
+		duplicate <- res
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if calls.Load() != 1 {
+		t.Fatalf("expected the duplicate to wait for the abandoned factory, got %d calls", calls.Load())
+	}
+	close(gate)
+
+	select {
+	case res := <-duplicate:
+		if res != "charged" {
+			t.Errorf("expected the late outcome to be replayed, got %q", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("duplicate did not see the late outcome")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected the factory to run once, got %d", calls.Load())
+	}
+}
+
+func TestIdempotencyReleasesKeyWhenNextPanics(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewIdempotencyExtension(NewMemoryIdempotencyStore(nil))))
+	defer scope.Dispose()
+
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	var panics atomic.Int32
+	explode := func(execCtx *pumped.ExecutionCtx, next func() (any, error)) (any, error) {
+		if panics.Add(1) == 1 {
+			panic("middleware failed")
+		}
+		return next()
+	}
+	flow := pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (string, error) {
+		return "charged", nil
+	},
+		pumped.WithFlowTag(pumped.FlowName(), "charge"),
+		pumped.WithFlowTag(Idempotent(), IdempotencyConfig{Key: KeyFromInput(), TTL: time.Hour}),
+		pumped.WithFlowMiddleware(explode),
+	)
+
+	func() {
+		defer func() { recover() }()
+		execWithInput(scope, flow, "order-1")
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if res, _, err := execWithInput(scope, flow, "order-1"); err != nil || res != "charged" {
+			t.Errorf("expected the retry to run, got %q, %v", res, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("duplicate blocked on a key whose owner panicked")
+	}
+}
+
+func TestIdempotencyFinishKeepsNewerReservation(t *testing.T) {
+	ext := NewIdempotencyExtension(NewMemoryIdempotencyStore(nil))
+
+	stale := make(chan struct{})
+	current := make(chan struct{})
+	ext.running["charge:1"] = current
+	ext.finish("charge:1", stale)
+
+	if ext.running["charge:1"] != current {
+		t.Error("expected a stale owner to leave the newer reservation in place")
+	}
+	select {
+	case <-stale:
+	default:
+		t.Error("expected the stale owner's waiters to be woken")
+	}
+}
+
+func TestIdempotencyForkHasOwnStore(t *testing.T) {
+	parent := pumped.NewScope(pumped.WithExtension(NewIdempotencyExtension(NewMemoryIdempotencyStore(nil))))
+	defer parent.Dispose()
+
+	var calls atomic.Int32
+	flow := idempotentFlow(&calls, func() (string, error) {
+		return "charged", nil
+	})
+	execWithInput(parent, flow, "order-1")
+
+	fork, err := parent.Fork()
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	defer fork.Dispose()
+	execWithInput(fork, flow, "order-1")
+	if calls.Load() != 2 {
+		t.Errorf("expected the fork to run its own execution, got %d calls", calls.Load())
+	}
+}
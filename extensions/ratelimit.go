@@ -0,0 +1,309 @@
+package extensions
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+// RateLimitAlgorithm selects how a rate limit counts executions
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket refills Limit tokens per Interval up to Burst
+	TokenBucket RateLimitAlgorithm = iota
+	// SlidingWindow allows at most Limit executions in any Interval
+	SlidingWindow
+)
+
+// RateLimitConfig declares a rate limit for a flow. Flows sharing a Name
+// share the limit; without a Name each flow has its own.
+type RateLimitConfig struct {
+	Name      string
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Interval  time.Duration
+	// Burst is the token bucket capacity (default Limit)
+	Burst int
+	// Wait makes executions wait for capacity instead of being rejected
+	Wait bool
+	// MaxWait bounds the wait; zero waits as long as the context allows
+	MaxWait time.Duration
+	// Key partitions the limit, e.g. per tenant. Executions with the same
+	// key share a limiter.
+	Key func(*pumped.ExecutionCtx) string
+}
+
+var rateLimitTag = pumped.NewTag[RateLimitConfig]("resilience.rate_limit")
+
+// RateLimit is the tag that declares a flow's rate limit
+// (pumped.WithFlowTag)
+func RateLimit() pumped.Tag[RateLimitConfig] { return rateLimitTag }
+
+// KeyFromTag partitions a rate limit by a tag looked up from the execution
+// context, its parents and the scope. Executions without the tag share the
+// empty key.
+func KeyFromTag[T any](tag pumped.Tag[T]) func(*pumped.ExecutionCtx) string {
+	return func(execCtx *pumped.ExecutionCtx) string {
+		val, ok := execCtx.Lookup(tag)
+		if !ok {
+			return ""
+		}
+		return fmt.Sprint(val)
+	}
+}
+
+// RateLimitedError is returned for executions rejected by a rate limit. It
+// matches pumped.ErrRejected, so the execution is recorded as rejected.
+type RateLimitedError struct {
+	Limit      string
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("rate limit %q exceeded for key %q, retry after %v", e.Limit, e.Key, e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limit %q exceeded, retry after %v", e.Limit, e.RetryAfter)
+}
+
+func (e *RateLimitedError) Is(target error) bool {
+	return target == pumped.ErrRejected
+}
+
+// RateLimitExtension enforces RateLimit tags before flow factories run.
+//
+// Usage:
+//
+//	tenantTag := pumped.NewTag[string]("tenant")
+//
+//	search := pumped.Flow1(db, searchHandler,
+//	    pumped.WithFlowTag(extensions.RateLimit(), extensions.RateLimitConfig{
+//	        Limit:    10,
+//	        Interval: time.Second,
+//	        Key:      extensions.KeyFromTag(tenantTag),
+//	    }),
+//	)
+//
+//	scope := pumped.NewScope(pumped.WithExtension(extensions.NewRateLimitExtension()))
+//
+// Limiters are created per limit and key, and dropped once they have been
+// idle long enough to be back at full capacity.
+type RateLimitExtension struct {
+	pumped.BaseExtension
+	clock pumped.Clock
+
+	mu        sync.Mutex
+	limiters  map[rateLimitKey]limiter
+	lastSweep time.Time
+}
+
+// rateLimitSweepInterval is how often idle limiters are looked for
+const rateLimitSweepInterval = time.Minute
+
+type rateLimitKey struct {
+	limit any
+	key   string
+}
+
+// limiter tracks one limit's capacity. Callers hold the extension's mu.
+type limiter interface {
+	// take consumes capacity, or reports how long until some is available
+	take(now time.Time) (bool, time.Duration)
+	// full reports whether the limiter has recovered all its capacity, so
+	// dropping it changes nothing
+	full(now time.Time) bool
+}
+
+// RateLimitOption configures a RateLimitExtension
+type RateLimitOption func(*RateLimitExtension)
+
+// WithRateLimitClock replaces the wall clock used to count executions and
+// to wait for capacity, for tests
+func WithRateLimitClock(clock pumped.Clock) RateLimitOption {
+	return func(e *RateLimitExtension) {
+		e.clock = clock
+	}
+}
+
+// NewRateLimitExtension creates a new rate limit extension
+func NewRateLimitExtension(opts ...RateLimitOption) *RateLimitExtension {
+	e := &RateLimitExtension{
+		BaseExtension: pumped.NewBaseExtension("rate-limit"),
+		clock:         pumped.SystemClock(),
+		limiters:      make(map[rateLimitKey]limiter),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Fork returns an instance for a forked scope, with the same clock and
+// every limit at full capacity
+func (e *RateLimitExtension) Fork() pumped.Extension {
+	return &RateLimitExtension{
+		BaseExtension: e.BaseExtension,
+		clock:         e.clock,
+		limiters:      make(map[rateLimitKey]limiter),
+	}
+}
+
+// Wrap checks the flow's rate limit before running it
+func (e *RateLimitExtension) Wrap(ctx context.Context, next func() (any, error), op *pumped.Operation) (any, error) {
+	if op.Kind != pumped.OpExec || op.Flow == nil {
+		return next()
+	}
+	val, ok := op.Flow.GetTag(rateLimitTag)
+	if !ok {
+		return next()
+	}
+	cfg := val.(RateLimitConfig)
+
+	var limit any = op.Flow
+	name := cfg.Name
+	if name != "" {
+		limit = name
+	} else {
+		name = targetName(op.Flow)
+	}
+
+	key := ""
+	if cfg.Key != nil {
+		key = cfg.Key(op.ExecutionCtx)
+	}
+	limKey := rateLimitKey{limit: limit, key: key}
+
+	var deadline time.Time
+	if cfg.Wait && cfg.MaxWait > 0 {
+		deadline = e.clock.Now().Add(cfg.MaxWait)
+	}
+
+	for {
+		now := e.clock.Now()
+		ok, retryAfter := e.take(limKey, cfg, now)
+		if ok {
+			return next()
+		}
+
+		rejected := &RateLimitedError{Limit: name, Key: key, RetryAfter: retryAfter}
+		if !cfg.Wait || (!deadline.IsZero() && now.Add(retryAfter).After(deadline)) {
+			return nil, rejected
+		}
+
+		select {
+		case <-e.clock.After(retryAfter):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// take consumes capacity from the limiter for key, creating it on first use.
+// It holds e.mu throughout so a sweep cannot drop the limiter between the
+// lookup and the take.
+func (e *RateLimitExtension) take(key rateLimitKey, cfg RateLimitConfig, now time.Time) (bool, time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if now.Sub(e.lastSweep) >= rateLimitSweepInterval {
+		e.lastSweep = now
+		for k, lim := range e.limiters {
+			if lim.full(now) {
+				delete(e.limiters, k)
+			}
+		}
+	}
+
+	lim, ok := e.limiters[key]
+	if !ok {
+		lim = newLimiter(cfg)
+		e.limiters[key] = lim
+	}
+	return lim.take(now)
+}
+
+func newLimiter(cfg RateLimitConfig) limiter {
+	limit := max(cfg.Limit, 1)
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	switch cfg.Algorithm {
+	case SlidingWindow:
+		return &slidingWindow{limit: limit, window: interval}
+	default:
+		burst := cfg.Burst
+		if burst <= 0 {
+			burst = limit
+		}
+		return &tokenBucket{
+			capacity: float64(burst),
+			tokens:   float64(burst),
+			perToken: float64(interval) / float64(limit),
+		}
+	}
+}
+
+type tokenBucket struct {
+	capacity float64
+	tokens   float64
+	perToken float64 // nanoseconds to refill one token
+	last     time.Time
+}
+
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration(math.Ceil((1 - b.tokens) * b.perToken))
+	return false, max(wait, time.Nanosecond)
+}
+
+func (b *tokenBucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.capacity
+}
+
+// refill adds the tokens earned since the last call
+func (b *tokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = math.Min(b.capacity, b.tokens+float64(now.Sub(b.last))/b.perToken)
+	}
+	if b.last.IsZero() || now.After(b.last) {
+		b.last = now
+	}
+}
+
+type slidingWindow struct {
+	limit  int
+	window time.Duration
+	events []time.Time
+}
+
+func (w *slidingWindow) take(now time.Time) (bool, time.Duration) {
+	cutoff := now.Add(-w.window)
+	i := 0
+	for i < len(w.events) && !w.events[i].After(cutoff) {
+		i++
+	}
+	w.events = w.events[i:]
+
+	if len(w.events) < w.limit {
+		w.events = append(w.events, now)
+		return true, 0
+	}
+	return false, w.events[0].Sub(cutoff)
+}
+
+func (w *slidingWindow) full(now time.Time) bool {
+	return len(w.events) == 0 || !w.events[len(w.events)-1].After(now.Add(-w.window))
+}
@@ -0,0 +1,291 @@
+package extensions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+func rateLimitedFlow(cfg RateLimitConfig, calls *int) *pumped.Flow[int] {
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	return pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (int, error) {
+		*calls++
+		return *calls, nil
+	},
+		pumped.WithFlowTag(pumped.FlowName(), "search"),
+		pumped.WithFlowTag(RateLimit(), cfg),
+	)
+}
+
+func TestRateLimitTokenBucketRejects(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	scope := pumped.NewScope(pumped.WithExtension(NewRateLimitExtension(WithRateLimitClock(clock))))
+	defer scope.Dispose()
+
+	var calls int
+	flow := rateLimitedFlow(RateLimitConfig{Limit: 2, Interval: time.Second}, &calls)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := pumped.Exec(scope, context.Background(), flow); err != nil {
+			t.Fatalf("execution %d failed: %v", i, err)
+		}
+	}
+
+	_, execCtx, err := pumped.Exec(scope, context.Background(), flow)
+	var limited *RateLimitedError
+	if !errors.As(err, &limited) || !errors.Is(err, pumped.ErrRejected) {
+		t.Fatalf("expected RateLimitedError, got %v", err)
+	}
+	if limited.RetryAfter != 500*time.Millisecond {
+		t.Errorf("expected retry after 500ms, got %v", limited.RetryAfter)
+	}
+	if calls != 2 {
+		t.Errorf("expected factory not to run when rejected, got %d calls", calls)
+	}
+
+	node := scope.GetExecutionTree().GetNode(execCtx.ID())
+	if node == nil {
+		t.Fatal("expected rejected execution to be recorded")
+	}
+	if status, _ := node.GetTag(pumped.Status()); status != pumped.ExecutionStatusRejected {
+		t.Errorf("expected rejected status, got %v", status)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if _, _, err := pumped.Exec(scope, context.Background(), flow); err != nil {
+		t.Errorf("expected refilled token, got %v", err)
+	}
+}
+
+func TestRateLimitSlidingWindow(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	scope := pumped.NewScope(pumped.WithExtension(NewRateLimitExtension(WithRateLimitClock(clock))))
+	defer scope.Dispose()
+
+	var calls int
+	flow := rateLimitedFlow(RateLimitConfig{
+		Algorithm: SlidingWindow,
+		Limit:     2,
+		Interval:  time.Minute,
+	}, &calls)
+
+	pumped.Exec(scope, context.Background(), flow)
+	clock.Advance(30 * time.Second)
+	pumped.Exec(scope, context.Background(), flow)
+
+	_, _, err := pumped.Exec(scope, context.Background(), flow)
+	var limited *RateLimitedError
+	if !errors.As(err, &limited) || limited.RetryAfter != 30*time.Second {
+		t.Fatalf("expected rejection with 30s retry, got %v", err)
+	}
+
+	clock.Advance(30 * time.Second)
+	if _, _, err := pumped.Exec(scope, context.Background(), flow); err != nil {
+		t.Errorf("expected oldest execution to leave the window, got %v", err)
+	}
+}
+
+func TestRateLimitPerKey(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	scope := pumped.NewScope(pumped.WithExtension(NewRateLimitExtension(WithRateLimitClock(clock))))
+	defer scope.Dispose()
+
+	tenant := pumped.NewTag[string]("tenant")
+	var calls int
+	flow := rateLimitedFlow(RateLimitConfig{
+		Limit:    1,
+		Interval: time.Minute,
+		Key:      KeyFromTag(tenant),
+	}, &calls)
+
+	exec := func(name string) error {
+		_, _, err := pumped.Exec(scope, context.Background(), flow, pumped.WithExecTag(tenant, name))
+		return err
+	}
+
+	if err := exec("acme"); err != nil {
+		t.Fatalf("first acme execution failed: %v", err)
+	}
+	if err := exec("globex"); err != nil {
+		t.Fatalf("globex has its own limit, got %v", err)
+	}
+
+	err := exec("acme")
+	var limited *RateLimitedError
+	if !errors.As(err, &limited) || limited.Key != "acme" || limited.Limit != "search" {
+		t.Fatalf("expected acme to be limited, got %v", err)
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewRateLimitExtension()))
+	defer scope.Dispose()
+
+	var calls int
+	flow := rateLimitedFlow(RateLimitConfig{
+		Limit:    1,
+		Interval: 20 * time.Millisecond,
+		Wait:     true,
+		MaxWait:  time.Second,
+	}, &calls)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, _, err := pumped.Exec(scope, context.Background(), flow); err != nil {
+			t.Fatalf("execution %d failed: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("expected executions to wait for tokens, took %v", elapsed)
+	}
+
+	short := rateLimitedFlow(RateLimitConfig{
+		Limit:    1,
+		Interval: time.Hour,
+		Wait:     true,
+		MaxWait:  10 * time.Millisecond,
+	}, &calls)
+	pumped.Exec(scope, context.Background(), short)
+	if _, _, err := pumped.Exec(scope, context.Background(), short); !errors.Is(err, pumped.ErrRejected) {
+		t.Errorf("expected rejection when the wait exceeds MaxWait, got %v", err)
+	}
+}
+
+func TestRateLimitWaitUsesClock(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	scope := pumped.NewScope(pumped.WithExtension(NewRateLimitExtension(WithRateLimitClock(clock))))
+	defer scope.Dispose()
+
+	var calls int
+	flow := rateLimitedFlow(RateLimitConfig{Limit: 1, Interval: time.Hour, Wait: true}, &calls)
+
+	if _, _, err := pumped.Exec(scope, context.Background(), flow); err != nil {
+		t.Fatalf("first execution failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := pumped.Exec(scope, context.Background(), flow)
+		done <- err
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for clock.Waiters() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Hour)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected the waiting execution to run, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("waiting execution did not follow the injected clock")
+	}
+}
+
+func TestRateLimitSubNanosecondRefill(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	scope := pumped.NewScope(pumped.WithExtension(NewRateLimitExtension(WithRateLimitClock(clock))))
+	defer scope.Dispose()
+
+	var calls int
+	flow := rateLimitedFlow(RateLimitConfig{Limit: 2000, Interval: time.Microsecond, Burst: 1}, &calls)
+
+	if _, _, err := pumped.Exec(scope, context.Background(), flow); err != nil {
+		t.Fatalf("first execution failed: %v", err)
+	}
+	_, _, err := pumped.Exec(scope, context.Background(), flow)
+	var limited *RateLimitedError
+	if !errors.As(err, &limited) || limited.RetryAfter <= 0 {
+		t.Fatalf("expected rejection until the next refill, got %v", err)
+	}
+
+	clock.Advance(time.Nanosecond)
+	if _, _, err := pumped.Exec(scope, context.Background(), flow); err != nil {
+		t.Errorf("expected a token after 1ns, got %v", err)
+	}
+}
+
+func TestRateLimitEvictsIdleLimiters(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	ext := NewRateLimitExtension(WithRateLimitClock(clock))
+	scope := pumped.NewScope(pumped.WithExtension(ext))
+	defer scope.Dispose()
+
+	tenant := pumped.NewTag[string]("tenant")
+	var calls int
+	flow := rateLimitedFlow(RateLimitConfig{Limit: 1, Interval: time.Second, Key: KeyFromTag(tenant)}, &calls)
+
+	for i := 0; i < 50; i++ {
+		pumped.Exec(scope, context.Background(), flow, pumped.WithExecTag(tenant, string(rune('a'+i))))
+	}
+	clock.Advance(time.Minute)
+	pumped.Exec(scope, context.Background(), flow, pumped.WithExecTag(tenant, "latest"))
+
+	ext.mu.Lock()
+	defer ext.mu.Unlock()
+	if len(ext.limiters) != 1 {
+		t.Errorf("expected idle limiters to be dropped, %d left", len(ext.limiters))
+	}
+}
+
+func TestRateLimitSweepDoesNotResetCapacity(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	ext := NewRateLimitExtension(WithRateLimitClock(clock))
+	cfg := RateLimitConfig{Limit: 1, Interval: time.Hour}
+	key := rateLimitKey{limit: "search"}
+
+	for round := 0; round < 20; round++ {
+		// Every round starts with a full limiter that is due for a sweep
+		clock.Advance(time.Hour)
+		now := clock.Now()
+
+		var admitted atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if ok, _ := ext.take(key, cfg, now); ok {
+					admitted.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if n := admitted.Load(); n != 1 {
+			t.Fatalf("round %d: expected exactly 1 admission, got %d", round, n)
+		}
+	}
+}
+
+func TestRateLimitForkHasOwnLimiters(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	parent := pumped.NewScope(pumped.WithExtension(NewRateLimitExtension(WithRateLimitClock(clock))))
+	defer parent.Dispose()
+
+	var calls int
+	flow := rateLimitedFlow(RateLimitConfig{Limit: 1, Interval: time.Hour}, &calls)
+	pumped.Exec(parent, context.Background(), flow)
+	if _, _, err := pumped.Exec(parent, context.Background(), flow); !errors.Is(err, pumped.ErrRejected) {
+		t.Fatalf("expected the parent to be limited, got %v", err)
+	}
+
+	fork, err := parent.Fork()
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	defer fork.Dispose()
+	if _, _, err := pumped.Exec(fork, context.Background(), flow); err != nil {
+		t.Errorf("expected the fork to start at full capacity, got %v", err)
+	}
+}
@@ -0,0 +1,482 @@
+package extensions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+var (
+	// ErrCircuitOpen is matched by errors returned while a breaker is open
+	ErrCircuitOpen = errors.New("circuit breaker open")
+	// ErrBulkheadFull is matched by errors returned when a bulkhead has no
+	// free slot
+	ErrBulkheadFull = errors.New("bulkhead full")
+)
+
+// CircuitOpenError is returned instead of running an operation whose
+// breaker is open. It matches ErrCircuitOpen and pumped.ErrRejected, so a
+// flow it refuses is recorded as rejected.
+type CircuitOpenError struct {
+	Name  string
+	Until time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker %q open until %s", e.Name, e.Until.Format(time.RFC3339Nano))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen || target == pumped.ErrRejected
+}
+
+// BulkheadFullError is returned when an operation could not get a slot.
+// It matches ErrBulkheadFull and pumped.ErrRejected.
+type BulkheadFullError struct {
+	Name          string
+	MaxConcurrent int
+}
+
+func (e *BulkheadFullError) Error() string {
+	return fmt.Sprintf("bulkhead %q full (%d concurrent)", e.Name, e.MaxConcurrent)
+}
+
+func (e *BulkheadFullError) Is(target error) bool {
+	return target == ErrBulkheadFull || target == pumped.ErrRejected
+}
+
+// BreakerConfig configures a circuit breaker. Operations sharing a Name
+// share a breaker; without a Name each executor or flow gets its own.
+type BreakerConfig struct {
+	Name string
+	// FailureThreshold is the number of consecutive failures that open the
+	// breaker (default 5)
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting probe
+	// calls through (default 30s)
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls bounds concurrent probe calls (default 1)
+	HalfOpenMaxCalls int
+	// SuccessThreshold is the number of successful probes that close the
+	// breaker again (default 1)
+	SuccessThreshold int
+	// IsFailure decides which errors count against the breaker. By default
+	// every error except context cancellation does.
+	IsFailure func(error) bool
+}
+
+// BulkheadConfig limits how many operations run at once. Operations sharing
+// a Name share the limit.
+type BulkheadConfig struct {
+	Name          string
+	MaxConcurrent int
+	// MaxWait is how long to wait for a slot; zero fails fast
+	MaxWait time.Duration
+}
+
+var (
+	circuitBreakerTag = pumped.NewTag[BreakerConfig]("resilience.circuit_breaker")
+	bulkheadTag       = pumped.NewTag[BulkheadConfig]("resilience.bulkhead")
+)
+
+// CircuitBreaker is the tag that enables circuit breaking for an executor
+// (pumped.WithTag) or a flow (pumped.WithFlowTag)
+func CircuitBreaker() pumped.Tag[BreakerConfig] { return circuitBreakerTag }
+
+// Bulkhead is the tag that enables a concurrency limit for an executor
+// (pumped.WithTag) or a flow (pumped.WithFlowTag)
+func Bulkhead() pumped.Tag[BulkheadConfig] { return bulkheadTag }
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// BreakerStats is a snapshot of a circuit breaker
+type BreakerStats struct {
+	Name     string
+	State    BreakerState
+	Failures int
+	OpenedAt time.Time
+	Rejected uint64
+}
+
+// ResilienceExtension applies circuit breakers and bulkheads configured
+// with the CircuitBreaker and Bulkhead tags around resolves and flow
+// executions.
+//
+// Usage:
+//
+//	client := pumped.Provide(newClient,
+//	    pumped.WithTag(extensions.CircuitBreaker(), extensions.BreakerConfig{
+//	        FailureThreshold: 3,
+//	        OpenTimeout:      10 * time.Second,
+//	    }),
+//	)
+//
+//	res := extensions.NewResilienceExtension()
+//	scope := pumped.NewScope(pumped.WithExtension(res))
+//
+//	_, err := pumped.Resolve(scope, client)
+//	if errors.Is(err, extensions.ErrCircuitOpen) {
+//	    // fail fast while the dependency is down
+//	}
+type ResilienceExtension struct {
+	pumped.BaseExtension
+	now func() time.Time
+
+	mu        sync.Mutex
+	breakers  map[any]*breaker
+	bulkheads map[any]*bulkhead
+}
+
+// ResilienceOption configures a ResilienceExtension
+type ResilienceOption func(*ResilienceExtension)
+
+// WithResilienceClock replaces time.Now, for tests
+func WithResilienceClock(now func() time.Time) ResilienceOption {
+	return func(e *ResilienceExtension) {
+		e.now = now
+	}
+}
+
+// NewResilienceExtension creates a new resilience extension
+func NewResilienceExtension(opts ...ResilienceOption) *ResilienceExtension {
+	e := &ResilienceExtension{
+		BaseExtension: pumped.NewBaseExtension("resilience"),
+		now:           time.Now,
+		breakers:      make(map[any]*breaker),
+		bulkheads:     make(map[any]*bulkhead),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Fork returns an instance for a forked scope, with the same clock and no
+// breaker or bulkhead state
+func (e *ResilienceExtension) Fork() pumped.Extension {
+	return &ResilienceExtension{
+		BaseExtension: e.BaseExtension,
+		now:           e.now,
+		breakers:      make(map[any]*breaker),
+		bulkheads:     make(map[any]*bulkhead),
+	}
+}
+
+type tagged interface {
+	GetTag(tag any) (any, bool)
+}
+
+// Wrap guards resolves and flow executions whose executor or flow carries
+// a resilience tag
+func (e *ResilienceExtension) Wrap(ctx context.Context, next func() (any, error), op *pumped.Operation) (any, error) {
+	var target tagged
+	switch {
+	case op.Kind == pumped.OpResolve && op.Executor != nil:
+		target = op.Executor
+	case op.Kind == pumped.OpExec && op.Flow != nil:
+		target = op.Flow
+	default:
+		return next()
+	}
+
+	run := next
+	if val, ok := target.GetTag(bulkheadTag); ok {
+		b := e.bulkheadFor(target, val.(BulkheadConfig))
+		run = func() (any, error) {
+			if err := b.acquire(ctx); err != nil {
+				return nil, err
+			}
+			defer b.release()
+			return next()
+		}
+	}
+
+	if val, ok := target.GetTag(circuitBreakerTag); ok {
+		br := e.breakerFor(target, val.(BreakerConfig))
+		gen, err := br.allow(e.now())
+		if err != nil {
+			return nil, err
+		}
+		result, err := run()
+		br.record(gen, err, e.now())
+		return result, err
+	}
+
+	return run()
+}
+
+// State returns the breaker for an executor, a flow or a breaker name
+func (e *ResilienceExtension) State(target any) (BreakerStats, bool) {
+	e.mu.Lock()
+	br, ok := e.breakers[e.key(target)]
+	e.mu.Unlock()
+	if !ok {
+		return BreakerStats{}, false
+	}
+	return br.stats(e.now()), true
+}
+
+// Breakers returns every breaker created so far
+func (e *ResilienceExtension) Breakers() []BreakerStats {
+	e.mu.Lock()
+	all := make([]*breaker, 0, len(e.breakers))
+	for _, br := range e.breakers {
+		all = append(all, br)
+	}
+	e.mu.Unlock()
+
+	now := e.now()
+	stats := make([]BreakerStats, len(all))
+	for i, br := range all {
+		stats[i] = br.stats(now)
+	}
+	return stats
+}
+
+// Reset closes the breaker for an executor, a flow or a breaker name
+func (e *ResilienceExtension) Reset(target any) {
+	e.mu.Lock()
+	br, ok := e.breakers[e.key(target)]
+	e.mu.Unlock()
+	if ok {
+		br.reset()
+	}
+}
+
+// key maps a target to the breaker or bulkhead it uses. Named configs are
+// keyed by name so they can be shared.
+func (e *ResilienceExtension) key(target any) any {
+	if t, ok := target.(tagged); ok {
+		if val, ok := t.GetTag(circuitBreakerTag); ok && val.(BreakerConfig).Name != "" {
+			return val.(BreakerConfig).Name
+		}
+	}
+	return target
+}
+
+func (e *ResilienceExtension) breakerFor(target tagged, cfg BreakerConfig) *breaker {
+	var key any = target
+	name := cfg.Name
+	if name != "" {
+		key = name
+	} else {
+		name = targetName(target)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	br, ok := e.breakers[key]
+	if !ok {
+		br = newBreaker(name, cfg)
+		e.breakers[key] = br
+	}
+	return br
+}
+
+func (e *ResilienceExtension) bulkheadFor(target tagged, cfg BulkheadConfig) *bulkhead {
+	var key any = target
+	name := cfg.Name
+	if name != "" {
+		key = "bulkhead:" + name
+	} else {
+		name = targetName(target)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	b, ok := e.bulkheads[key]
+	if !ok {
+		b = &bulkhead{name: name, cfg: cfg, slots: make(chan struct{}, max(cfg.MaxConcurrent, 1))}
+		e.bulkheads[key] = b
+	}
+	return b
+}
+
+func targetName(target tagged) string {
+	if name, ok := target.GetTag(pumped.FlowName()); ok {
+		return name.(string)
+	}
+	return fmt.Sprintf("%p", target)
+}
+
+type breaker struct {
+	name string
+	cfg  BreakerConfig
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+	rejected  uint64
+
+	// generation changes with every state transition; completions of
+	// calls admitted under an older generation are ignored
+	generation uint64
+}
+
+func newBreaker(name string, cfg BreakerConfig) *breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	if cfg.HalfOpenMaxCalls <= 0 {
+		cfg.HalfOpenMaxCalls = 1
+	}
+	if cfg.SuccessThreshold <= 0 {
+		cfg.SuccessThreshold = 1
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		}
+	}
+	return &breaker{name: name, cfg: cfg, state: BreakerClosed}
+}
+
+// advance moves an open breaker to half-open once its timeout elapsed.
+// Callers hold b.mu.
+func (b *breaker) advance(now time.Time) {
+	if b.state == BreakerOpen && !now.Before(b.openedAt.Add(b.cfg.OpenTimeout)) {
+		b.state = BreakerHalfOpen
+		b.successes = 0
+		b.probes = 0
+		b.generation++
+	}
+}
+
+// allow admits a call and returns the generation to pass to record
+func (b *breaker) allow(now time.Time) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+	switch b.state {
+	case BreakerOpen:
+		b.rejected++
+		return 0, &CircuitOpenError{Name: b.name, Until: b.openedAt.Add(b.cfg.OpenTimeout)}
+	case BreakerHalfOpen:
+		if b.probes >= b.cfg.HalfOpenMaxCalls {
+			b.rejected++
+			return 0, &CircuitOpenError{Name: b.name, Until: now}
+		}
+		b.probes++
+	}
+	return b.generation, nil
+}
+
+func (b *breaker) record(gen uint64, err error, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if gen != b.generation {
+		return
+	}
+
+	failed := err != nil && !errors.Is(err, ErrBulkheadFull) && b.cfg.IsFailure(err)
+
+	switch b.state {
+	case BreakerHalfOpen:
+		b.probes--
+		if failed {
+			b.trip(now)
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.SuccessThreshold {
+			b.state = BreakerClosed
+			b.failures = 0
+			b.generation++
+		}
+	case BreakerClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.trip(now)
+		}
+	}
+}
+
+func (b *breaker) trip(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.failures = 0
+	b.generation++
+}
+
+func (b *breaker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probes = 0
+	b.generation++
+}
+
+func (b *breaker) stats(now time.Time) BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+	return BreakerStats{
+		Name:     b.name,
+		State:    b.state,
+		Failures: b.failures,
+		OpenedAt: b.openedAt,
+		Rejected: b.rejected,
+	}
+}
+
+type bulkhead struct {
+	name  string
+	cfg   BulkheadConfig
+	slots chan struct{}
+}
+
+func (b *bulkhead) acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if b.cfg.MaxWait <= 0 {
+		return &BulkheadFullError{Name: b.name, MaxConcurrent: cap(b.slots)}
+	}
+
+	timer := time.NewTimer(b.cfg.MaxWait)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return &BulkheadFullError{Name: b.name, MaxConcurrent: cap(b.slots)}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *bulkhead) release() {
+	<-b.slots
+}
@@ -0,0 +1,313 @@
+package extensions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+type manualTime struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []manualWaiter
+}
+
+type manualWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func (m *manualTime) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+func (m *manualTime) After(d time.Duration) <-chan time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	m.waiters = append(m.waiters, manualWaiter{at: m.now.Add(d), ch: ch})
+	return ch
+}
+
+// Waiters returns how many After channels have not fired yet
+func (m *manualTime) Waiters() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.waiters)
+}
+
+func (m *manualTime) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+
+	pending := m.waiters[:0]
+	for _, w := range m.waiters {
+		if w.at.After(m.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- m.now
+	}
+	m.waiters = pending
+}
+
+func TestCircuitBreakerOnResolve(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	res := NewResilienceExtension(WithResilienceClock(clock.Now))
+	scope := pumped.NewScope(pumped.WithExtension(res))
+	defer scope.Dispose()
+
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	client := pumped.Provide(
+		func(ctx *pumped.ResolveCtx) (string, error) {
+			calls.Add(1)
+			if !healthy.Load() {
+				return "", errors.New("connection refused")
+			}
+			return "client", nil
+		},
+		pumped.WithTag(CircuitBreaker(), BreakerConfig{
+			FailureThreshold: 2,
+			OpenTimeout:      10 * time.Second,
+		}),
+	)
+
+	for i := 0; i < 2; i++ {
+		if _, err := pumped.Resolve(scope, client); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("attempt %d: expected factory error, got %v", i, err)
+		}
+	}
+
+	_, err := pumped.Resolve(scope, client)
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected CircuitOpenError, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected factory skipped while open, got %d calls", calls.Load())
+	}
+
+	stats, ok := res.State(client)
+	if !ok || stats.State != BreakerOpen || stats.Rejected != 1 {
+		t.Errorf("expected open breaker with 1 rejection, got %+v", stats)
+	}
+
+	clock.Advance(10 * time.Second)
+	if stats, _ := res.State(client); stats.State != BreakerHalfOpen {
+		t.Errorf("expected half-open after timeout, got %s", stats.State)
+	}
+
+	// A failing probe reopens the breaker
+	if _, err := pumped.Resolve(scope, client); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected probe to run and fail, got %v", err)
+	}
+	if stats, _ := res.State(client); stats.State != BreakerOpen {
+		t.Errorf("expected breaker reopened, got %s", stats.State)
+	}
+
+	clock.Advance(10 * time.Second)
+	healthy.Store(true)
+	if val, err := pumped.Resolve(scope, client); err != nil || val != "client" {
+		t.Fatalf("expected successful probe, got %v, %v", val, err)
+	}
+	if stats, _ := res.State(client); stats.State != BreakerClosed {
+		t.Errorf("expected breaker closed, got %s", stats.State)
+	}
+}
+
+func TestCircuitBreakerSharedByNameOnFlows(t *testing.T) {
+	res := NewResilienceExtension()
+	scope := pumped.NewScope(pumped.WithExtension(res))
+	defer scope.Dispose()
+
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	cfg := BreakerConfig{Name: "payments", FailureThreshold: 1, OpenTimeout: time.Minute}
+
+	charge := pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (int, error) {
+		return 0, errors.New("gateway down")
+	}, pumped.WithFlowTag(CircuitBreaker(), cfg))
+
+	refund := pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (int, error) {
+		return 1, nil
+	}, pumped.WithFlowTag(CircuitBreaker(), cfg))
+
+	if _, _, err := pumped.Exec(scope, context.Background(), charge); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected flow error, got %v", err)
+	}
+
+	_, execCtx, err := pumped.Exec(scope, context.Background(), refund)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected shared breaker to reject refund, got %v", err)
+	}
+	if !errors.Is(err, pumped.ErrRejected) {
+		t.Errorf("expected open breaker error to match ErrRejected, got %v", err)
+	}
+	if status, _ := execCtx.Get(pumped.Status()); status != pumped.ExecutionStatusRejected {
+		t.Errorf("expected refused execution to be rejected, got %v", status)
+	}
+
+	stats, ok := res.State("payments")
+	if !ok || stats.State != BreakerOpen {
+		t.Errorf("expected named breaker open, got %+v", stats)
+	}
+
+	res.Reset("payments")
+	if _, _, err := pumped.Exec(scope, context.Background(), refund); err != nil {
+		t.Errorf("expected reset breaker to allow calls, got %v", err)
+	}
+	if len(res.Breakers()) != 1 {
+		t.Errorf("expected a single shared breaker, got %d", len(res.Breakers()))
+	}
+}
+
+func TestCircuitBreakerIgnoresStaleCompletions(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newBreaker("payments", BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second})
+
+	slow, err := b.allow(now)
+	if err != nil {
+		t.Fatalf("expected closed breaker to admit, got %v", err)
+	}
+	failing, _ := b.allow(now)
+	b.record(failing, errors.New("gateway down"), now)
+
+	now = now.Add(time.Second)
+	probe, err := b.allow(now)
+	if err != nil {
+		t.Fatalf("expected half-open breaker to admit a probe, got %v", err)
+	}
+
+	// The slow call admitted while closed must not free the probe slot
+	b.record(slow, nil, now)
+	if _, err := b.allow(now); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected a second probe to be rejected, got %v", err)
+	}
+
+	b.reset()
+	b.record(probe, nil, now)
+	if b.probes != 0 {
+		t.Errorf("expected a probe finishing after reset to be ignored, probes %d", b.probes)
+	}
+}
+
+func TestBulkheadLimitsConcurrentFlows(t *testing.T) {
+	res := NewResilienceExtension()
+	scope := pumped.NewScope(pumped.WithExtension(res))
+	defer scope.Dispose()
+
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	flow := pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (int, error) {
+		started <- struct{}{}
+		<-release
+		return 1, nil
+	}, pumped.WithFlowTag(Bulkhead(), BulkheadConfig{MaxConcurrent: 1}))
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := pumped.Exec(scope, context.Background(), flow)
+		done <- err
+	}()
+	<-started
+
+	_, execCtx, err := pumped.Exec(scope, context.Background(), flow)
+	var fullErr *BulkheadFullError
+	if !errors.As(err, &fullErr) || fullErr.MaxConcurrent != 1 {
+		t.Fatalf("expected BulkheadFullError, got %v", err)
+	}
+	if status, _ := execCtx.Get(pumped.Status()); status != pumped.ExecutionStatusRejected {
+		t.Errorf("expected refused execution to be rejected, got %v", status)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("first execution failed: %v", err)
+	}
+
+	go func() { <-started }()
+	if _, _, err := pumped.Exec(scope, context.Background(), flow); err != nil {
+		t.Errorf("expected slot to be released, got %v", err)
+	}
+}
+
+func TestBulkheadWaitsForSlot(t *testing.T) {
+	res := NewResilienceExtension()
+	scope := pumped.NewScope(pumped.WithExtension(res))
+	defer scope.Dispose()
+
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	var current, peak atomic.Int32
+	flow := pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (int, error) {
+		n := current.Add(1)
+		if n > peak.Load() {
+			peak.Store(n)
+		}
+		time.Sleep(5 * time.Millisecond)
+		current.Add(-1)
+		return 1, nil
+	}, pumped.WithFlowTag(Bulkhead(), BulkheadConfig{MaxConcurrent: 2, MaxWait: time.Second}))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := pumped.Exec(scope, context.Background(), flow)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("expected queued executions to succeed, got %v", err)
+		}
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent executions, got %d", peak.Load())
+	}
+}
+
+func TestResilienceForkHasOwnBreakers(t *testing.T) {
+	res := NewResilienceExtension()
+	parent := pumped.NewScope(pumped.WithExtension(res))
+	defer parent.Dispose()
+
+	client := pumped.Provide(func(ctx *pumped.ResolveCtx) (string, error) {
+		return "", errors.New("connection refused")
+	}, pumped.WithTag(CircuitBreaker(), BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Hour}))
+
+	pumped.Resolve(parent, client)
+	if _, err := pumped.Resolve(parent, client); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the parent's breaker to open, got %v", err)
+	}
+
+	fork, err := parent.Fork()
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	defer fork.Dispose()
+	if _, err := pumped.Resolve(fork, client); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected the fork to start with a closed breaker, got %v", err)
+	}
+}
@@ -0,0 +1,313 @@
+package extensions
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+// ResultCache stores flow results by key. Implementations backed by
+// external stores are responsible for encoding values.
+type ResultCache interface {
+	// Get returns the value stored under key, reporting false on a miss
+	Get(ctx context.Context, key string) (any, bool, error)
+	// Set stores value under key; a zero ttl never expires
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+}
+
+// LRUCache is an in-memory ResultCache that evicts the least recently used
+// entry once it holds capacity entries
+type LRUCache struct {
+	capacity int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     any
+	expiresAt time.Time
+}
+
+// LRUOption configures an LRUCache
+type LRUOption func(*LRUCache)
+
+// WithLRUClock replaces time.Now, for tests
+func WithLRUClock(now func() time.Time) LRUOption {
+	return func(c *LRUCache) {
+		c.now = now
+	}
+}
+
+// NewLRUCache creates an in-memory cache holding up to capacity entries
+func NewLRUCache(capacity int, opts ...LRUOption) *LRUCache {
+	c := &LRUCache{
+		capacity: max(capacity, 1),
+		now:      time.Now,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *LRUCache) Get(ctx context.Context, key string) (any, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+func (c *LRUCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet
+// evicted
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// MemoizeConfig opts a flow into result caching
+type MemoizeConfig struct {
+	// Key derives the cache key from the execution; returning false skips
+	// the cache for that execution
+	Key func(*pumped.ExecutionCtx) (string, bool)
+	// TTL is how long results stay cached; zero keeps them until evicted
+	TTL time.Duration
+	// Name prefixes keys so flows can share or separate entries (default
+	// the flow name)
+	Name string
+}
+
+var (
+	memoizeTag    = pumped.NewTag[MemoizeConfig]("cache.memoize")
+	cacheHitTag   = pumped.NewTag[bool]("cache.hit")
+	collapsedTag  = pumped.NewTag[bool]("cache.collapsed")
+	memoKeyTag    = pumped.NewTag[string]("cache.key")
+	memoConfigTag = pumped.NewTag[MemoizeConfig]("cache.config")
+)
+
+// Memoize is the tag that opts a flow into result caching
+// (pumped.WithFlowTag)
+func Memoize() pumped.Tag[MemoizeConfig] { return memoizeTag }
+
+// CacheHit is set on executions served from the cache
+func CacheHit() pumped.Tag[bool] { return cacheHitTag }
+
+// Collapsed is set on executions that shared the result of a concurrent
+// identical execution instead of running
+func Collapsed() pumped.Tag[bool] { return collapsedTag }
+
+// CacheKey is set on executions that used the cache to the key they used
+func CacheKey() pumped.Tag[string] { return memoKeyTag }
+
+// KeyFromInput derives cache keys from the JSON encoding of the Input tag,
+// looked up from the execution and its parents
+func KeyFromInput() func(*pumped.ExecutionCtx) (string, bool) {
+	return func(execCtx *pumped.ExecutionCtx) (string, bool) {
+		input, ok := execCtx.Lookup(pumped.Input())
+		if !ok {
+			return "", false
+		}
+		data, err := json.Marshal(input)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
+
+// ResultCacheExtension serves memoized flows from a ResultCache and
+// collapses concurrent executions with the same key into one.
+//
+// Usage:
+//
+//	lookup := pumped.Flow1(db, lookupHandler,
+//	    pumped.WithFlowTag(pumped.FlowName(), "lookup"),
+//	    pumped.WithFlowTag(extensions.Memoize(), extensions.MemoizeConfig{
+//	        Key: extensions.KeyFromInput(),
+//	        TTL: time.Minute,
+//	    }),
+//	)
+//
+//	cache := extensions.NewResultCacheExtension(extensions.NewLRUCache(1024))
+//	scope := pumped.NewScope(pumped.WithExtension(cache))
+//
+// Hits complete through SkipExecution and CachedOutput, so the factory does
+// not run and the node is tagged with CacheHit. Only successful results are
+// cached. Executions started with WithExecPreset bypass the cache.
+type ResultCacheExtension struct {
+	pumped.BaseExtension
+	cache ResultCache
+
+	mu       sync.Mutex
+	inflight map[string]*memoCall
+}
+
+type memoCall struct {
+	done   chan struct{}
+	result any
+	err    error
+	// cancelled is set when the leader failed with its own context's error,
+	// which says nothing about the result followers are waiting for
+	cancelled bool
+}
+
+// NewResultCacheExtension creates a result cache extension backed by cache
+func NewResultCacheExtension(cache ResultCache) *ResultCacheExtension {
+	return &ResultCacheExtension{
+		BaseExtension: pumped.NewBaseExtension("result-cache"),
+		cache:         cache,
+		inflight:      make(map[string]*memoCall),
+	}
+}
+
+// Fork returns an instance for a forked scope. An LRUCache is replaced by
+// an empty one with the same capacity, so forks do not see each other's
+// results; other caches are shared.
+func (e *ResultCacheExtension) Fork() pumped.Extension {
+	cache := e.cache
+	if lru, ok := cache.(*LRUCache); ok {
+		cache = NewLRUCache(lru.capacity, WithLRUClock(lru.now))
+	}
+	return NewResultCacheExtension(cache)
+}
+
+// OnFlowStart looks the execution up in the cache and marks hits to skip
+// the factory
+func (e *ResultCacheExtension) OnFlowStart(execCtx *pumped.ExecutionCtx, flow pumped.AnyFlow) error {
+	val, ok := flow.GetTag(memoizeTag)
+	if !ok {
+		return nil
+	}
+	cfg := val.(MemoizeConfig)
+	if cfg.Key == nil || execCtx.HasExecPresets() {
+		// Results computed with overridden dependencies must not be shared
+		return nil
+	}
+
+	key, ok := cfg.Key(execCtx)
+	if !ok {
+		return nil
+	}
+	name := cfg.Name
+	if name == "" {
+		name = targetName(flow)
+	}
+	key = name + ":" + key
+
+	execCtx.Set(memoKeyTag, key)
+	execCtx.Set(memoConfigTag, cfg)
+
+	cached, hit, err := e.cache.Get(execCtx.Context(), key)
+	if err != nil || !hit {
+		return nil
+	}
+
+	execCtx.Set(cacheHitTag, true)
+	execCtx.Set(pumped.SkipExecution(), true)
+	execCtx.Set(pumped.CachedOutput(), cached)
+	return nil
+}
+
+// Wrap runs a cache miss once per key, sharing the result with concurrent
+// executions of the same key, and stores successful results
+func (e *ResultCacheExtension) Wrap(ctx context.Context, next func() (any, error), op *pumped.Operation) (any, error) {
+	if op.Kind != pumped.OpExec || op.ExecutionCtx == nil {
+		return next()
+	}
+	val, ok := op.ExecutionCtx.Get(memoKeyTag)
+	if !ok {
+		return next()
+	}
+	key := val.(string)
+
+	for {
+		e.mu.Lock()
+		call, ok := e.inflight[key]
+		if !ok {
+			break
+		}
+		e.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.cancelled {
+			// The leader gave up; run again, possibly as the new leader
+			continue
+		}
+		op.ExecutionCtx.Set(collapsedTag, true)
+		return call.result, call.err
+	}
+	call := &memoCall{done: make(chan struct{})}
+	e.inflight[key] = call
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		delete(e.inflight, key)
+		e.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.result, call.err = next()
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(call.err, ctxErr) {
+		call.cancelled = true
+	}
+	if call.err == nil {
+		cfgVal, _ := op.ExecutionCtx.Get(memoConfigTag)
+		cfg := cfgVal.(MemoizeConfig)
+		// A failed write only costs a future miss; the execution succeeded
+		_ = e.cache.Set(ctx, key, call.result, cfg.TTL)
+	}
+	return call.result, call.err
+}
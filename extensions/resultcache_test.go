@@ -0,0 +1,266 @@
+package extensions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+func memoizedFlow(calls *atomic.Int32, gate <-chan struct{}) *pumped.Flow[int] {
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	return pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (int, error) {
+		calls.Add(1)
+		if gate != nil {
+			<-gate
+		}
+		n, _ := execCtx.Lookup(pumped.Input())
+		return n.(int) * 10, nil
+	},
+		pumped.WithFlowTag(pumped.FlowName(), "square"),
+		pumped.WithFlowTag(Memoize(), MemoizeConfig{Key: KeyFromInput(), TTL: time.Minute}),
+	)
+}
+
+func TestResultCacheHitSkipsFactory(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewResultCacheExtension(NewLRUCache(16))))
+	defer scope.Dispose()
+
+	var calls atomic.Int32
+	flow := memoizedFlow(&calls, nil)
+
+	first, _, err := pumped.Exec(scope, context.Background(), flow, pumped.WithExecTag(pumped.Input(), any(4)))
+	if err != nil || first != 40 {
+		t.Fatalf("expected 40, got %d, %v", first, err)
+	}
+
+	second, execCtx, err := pumped.Exec(scope, context.Background(), flow, pumped.WithExecTag(pumped.Input(), any(4)))
+	if err != nil || second != 40 {
+		t.Fatalf("expected cached 40, got %d, %v", second, err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected factory to run once, got %d", calls.Load())
+	}
+
+	node := scope.GetExecutionTree().GetNode(execCtx.ID())
+	if hit, _ := node.GetTag(CacheHit()); hit != true {
+		t.Error("expected node marked as cache hit")
+	}
+	if status, _ := node.GetTag(pumped.Status()); status != pumped.ExecutionStatusSuccess {
+		t.Errorf("expected success status, got %v", status)
+	}
+
+	if _, _, err := pumped.Exec(scope, context.Background(), flow, pumped.WithExecTag(pumped.Input(), any(5))); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected different input to miss, got %d calls", calls.Load())
+	}
+}
+
+func TestResultCacheOnSubFlows(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewResultCacheExtension(NewLRUCache(16))))
+	defer scope.Dispose()
+
+	var calls atomic.Int32
+	child := memoizedFlow(&calls, nil)
+
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	parent := pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (int, error) {
+		execCtx.Set(pumped.Input(), 3)
+		total := 0
+		for i := 0; i < 3; i++ {
+			v, _, err := pumped.Exec1(execCtx, child)
+			if err != nil {
+				return 0, err
+			}
+			total += v
+		}
+		return total, nil
+	})
+
+	total, _, err := pumped.Exec(scope, context.Background(), parent)
+	if err != nil || total != 90 {
+		t.Fatalf("expected 90, got %d, %v", total, err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected sub-flow to run once, got %d", calls.Load())
+	}
+}
+
+func TestResultCacheCollapsesConcurrentExecutions(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewResultCacheExtension(NewLRUCache(16))))
+	defer scope.Dispose()
+
+	var calls atomic.Int32
+	gate := make(chan struct{})
+	flow := memoizedFlow(&calls, gate)
+
+	var wg sync.WaitGroup
+	results := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, _, err := pumped.Exec(scope, context.Background(), flow, pumped.WithExecTag(pumped.Input(), any(7)))
+			if err != nil {
+				t.Errorf("Exec failed: %v", err)
+			}
+			results <- v
+		}()
+	}
+
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(gate)
+	wg.Wait()
+	close(results)
+
+	for v := range results {
+		if v != 70 {
+			t.Errorf("expected 70, got %d", v)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected concurrent executions collapsed into one, got %d", calls.Load())
+	}
+}
+
+func TestLRUCacheEvictionAndTTL(t *testing.T) {
+	clock := &manualTime{now: time.Unix(0, 0)}
+	cache := NewLRUCache(2, WithLRUClock(clock.Now))
+	ctx := context.Background()
+
+	cache.Set(ctx, "a", 1, 0)
+	cache.Set(ctx, "b", 2, time.Second)
+	cache.Get(ctx, "a")
+	cache.Set(ctx, "c", 3, 0)
+
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Error("expected least recently used entry evicted")
+	}
+	if v, ok, _ := cache.Get(ctx, "a"); !ok || v != 1 {
+		t.Errorf("expected a to survive, got %v", v)
+	}
+
+	cache.Set(ctx, "d", 4, time.Second)
+	clock.Advance(time.Second)
+	if _, ok, _ := cache.Get(ctx, "d"); ok {
+		t.Error("expected entry to expire")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("expected 1 entry left, got %d", cache.Len())
+	}
+}
+
+func TestResultCacheFollowerOutlivesCancelledLeader(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewResultCacheExtension(NewLRUCache(16))))
+	defer scope.Dispose()
+
+	var calls atomic.Int32
+	gate := make(chan struct{})
+	flow := memoizedFlow(&calls, gate)
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, _, err := pumped.Exec(scope, leaderCtx, flow, pumped.WithExecTag(pumped.Input(), any(7)))
+		leaderErr <- err
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	type outcome struct {
+		value   int
+		execCtx *pumped.ExecutionCtx
+		err     error
+	}
+	follower := make(chan outcome, 1)
+	go func() {
+		v, execCtx, err := pumped.Exec(scope, context.Background(), flow, pumped.WithExecTag(pumped.Input(), any(7)))
+		follower <- outcome{v, execCtx, err}
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the leader to be cancelled, got %v", err)
+	}
+	for calls.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	close(gate)
+
+	got := <-follower
+	if got.err != nil || got.value != 70 {
+		t.Fatalf("expected the follower to run on its own, got %d, %v", got.value, got.err)
+	}
+	if collapsed, _ := got.execCtx.Get(Collapsed()); collapsed == true {
+		t.Error("follower that ran itself should not be marked collapsed")
+	}
+}
+
+func TestResultCacheBypassesExecPresets(t *testing.T) {
+	scope := pumped.NewScope(pumped.WithExtension(NewResultCacheExtension(NewLRUCache(16))))
+	defer scope.Dispose()
+
+	factor := pumped.Provide(func(ctx *pumped.ResolveCtx) (int, error) {
+		return 10, nil
+	})
+	var calls atomic.Int32
+	flow := pumped.Flow1(factor, func(execCtx *pumped.ExecutionCtx, f *pumped.Controller[int]) (int, error) {
+		calls.Add(1)
+		n, _ := execCtx.Lookup(pumped.Input())
+		v, err := f.Get()
+		return n.(int) * v, err
+	},
+		pumped.WithFlowTag(pumped.FlowName(), "scale"),
+		pumped.WithFlowTag(Memoize(), MemoizeConfig{Key: KeyFromInput()}),
+	)
+
+	overridden, _, err := pumped.Exec(scope, context.Background(), flow,
+		pumped.WithExecTag(pumped.Input(), any(2)),
+		pumped.WithExecPreset(factor, 100),
+	)
+	if err != nil || overridden != 200 {
+		t.Fatalf("expected 200 with the preset, got %d, %v", overridden, err)
+	}
+
+	normal, _, err := pumped.Exec(scope, context.Background(), flow, pumped.WithExecTag(pumped.Input(), any(2)))
+	if err != nil || normal != 20 {
+		t.Fatalf("expected 20 without the preset, got %d, %v", normal, err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected the preset execution to bypass the cache, got %d calls", calls.Load())
+	}
+}
+
+func TestResultCacheForkHasOwnLRU(t *testing.T) {
+	parent := pumped.NewScope(pumped.WithExtension(NewResultCacheExtension(NewLRUCache(16))))
+	defer parent.Dispose()
+
+	var calls atomic.Int32
+	flow := memoizedFlow(&calls, nil)
+	pumped.Exec(parent, context.Background(), flow, pumped.WithExecTag(pumped.Input(), any(4)))
+
+	fork, err := parent.Fork()
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	defer fork.Dispose()
+	pumped.Exec(fork, context.Background(), flow, pumped.WithExecTag(pumped.Input(), any(4)))
+	if calls.Load() != 2 {
+		t.Errorf("expected the fork not to see the parent's results, got %d calls", calls.Load())
+	}
+}
@@ -0,0 +1,85 @@
+package pumped
+
+import "fmt"
+
+// FallbackError is returned when both branches of an OrElse executor fail
+type FallbackError struct {
+	Primary  error
+	Fallback error
+}
+
+func (e *FallbackError) Error() string {
+	return fmt.Sprintf("primary failed: %v; fallback failed: %v", e.Primary, e.Fallback)
+}
+
+func (e *FallbackError) Unwrap() []error {
+	return []error{e.Primary, e.Fallback}
+}
+
+// FallbackBranch records which branch an OrElse executor resolved
+type FallbackBranch struct {
+	// UsedFallback is set when the primary failed
+	UsedFallback bool
+	// PrimaryErr is the primary's error when the fallback was used
+	PrimaryErr error
+}
+
+type fallbackBranchKey struct {
+	exec AnyExecutor
+}
+
+// OrElse returns an executor that resolves e, or fallback when e fails.
+// The branches are reactive dependencies: updating or releasing e makes the
+// executor try the primary again. The chosen branch is recorded on the
+// scope that caches the value, see Scope.FallbackBranch; executions with
+// presets that affect it keep their branch to themselves.
+func (e *Executor[T]) OrElse(fallback *Executor[T], opts ...ExecutorOption) *Executor[T] {
+	exec := &Executor[T]{
+		deps: []Dependency{e.Lazy(), fallback.Lazy()},
+		tags: make(map[any]any),
+	}
+	exec.factory = func(ctx *ResolveCtx) (T, error) {
+		// The branches are resolved on demand, so the reactive edges are
+		// added here rather than declared as deps, which would resolve both
+		// eagerly
+		ctx.scope.mu.Lock()
+		ctx.scope.graph.AddDependency(exec, e)
+		ctx.scope.mu.Unlock()
+
+		// Record the branch with the cached value, so an execution whose
+		// presets change the branch keeps it to its own overlay
+		tags := &ctx.scope.owner(exec).tags
+
+		val, err := resolveFor(ctx.scope, e, ctx)
+		if err == nil {
+			tags.Store(fallbackBranchKey{exec: exec}, FallbackBranch{})
+			return val, nil
+		}
+
+		tags.Store(fallbackBranchKey{exec: exec}, FallbackBranch{UsedFallback: true, PrimaryErr: err})
+		ctx.scope.mu.Lock()
+		ctx.scope.graph.AddDependency(exec, fallback)
+		ctx.scope.mu.Unlock()
+		val, fbErr := resolveFor(ctx.scope, fallback, ctx)
+		if fbErr != nil {
+			return val, &FallbackError{Primary: err, Fallback: fbErr}
+		}
+		return val, nil
+	}
+
+	WithLifetime(LifetimeOf(e))(exec)
+	for _, opt := range opts {
+		opt(exec)
+	}
+	return exec
+}
+
+// FallbackBranch reports which branch an OrElse executor took when it was
+// last resolved in this scope
+func (s *Scope) FallbackBranch(exec AnyExecutor) (FallbackBranch, bool) {
+	val, ok := s.GetTag(fallbackBranchKey{exec: exec})
+	if !ok {
+		return FallbackBranch{}, false
+	}
+	return val.(FallbackBranch), true
+}
@@ -0,0 +1,201 @@
+package pumped
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOptionalDependencyReportsAbsence(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	metrics := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "", errors.New("metrics backend unreachable")
+	})
+
+	service := Derive1(metrics.Optional(), func(ctx *ResolveCtx, m *Controller[string]) (string, error) {
+		if sink, ok := m.TryGet(); ok {
+			return "service+" + sink, nil
+		}
+		return "service", nil
+	})
+
+	got, err := Resolve(scope, service)
+	if err != nil {
+		t.Fatalf("expected optional failure to be tolerated, got %v", err)
+	}
+	if got != "service" {
+		t.Errorf("expected service without metrics, got %q", got)
+	}
+
+	flow := Flow1(metrics.Optional(), func(execCtx *ExecutionCtx, m *Controller[string]) (bool, error) {
+		_, ok := m.TryGet()
+		return ok, nil
+	})
+	present, _, err := Exec(scope, context.Background(), flow)
+	if err != nil || present {
+		t.Errorf("expected flow to run without the optional dependency, got %v, %v", present, err)
+	}
+}
+
+func TestOrElseFallsBack(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	primaryErr := errors.New("redis down")
+	redis := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "", primaryErr
+	})
+	memory := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "memory", nil
+	})
+	cache := redis.OrElse(memory)
+
+	got, err := Resolve(scope, cache)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if got != "memory" {
+		t.Errorf("expected fallback value, got %q", got)
+	}
+
+	branch, ok := scope.FallbackBranch(cache)
+	if !ok || !branch.UsedFallback || !errors.Is(branch.PrimaryErr, primaryErr) {
+		t.Errorf("expected fallback branch recorded, got %+v", branch)
+	}
+}
+
+func TestOrElsePrefersPrimary(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	primary := Provide(func(ctx *ResolveCtx) (int, error) { return 1, nil })
+	fallback := Provide(func(ctx *ResolveCtx) (int, error) { return 2, nil })
+	exec := primary.OrElse(fallback)
+
+	if got, _ := Resolve(scope, exec); got != 1 {
+		t.Errorf("expected primary value, got %d", got)
+	}
+	if branch, _ := scope.FallbackBranch(exec); branch.UsedFallback {
+		t.Error("expected primary branch recorded")
+	}
+	if Accessor(scope, fallback).IsCached() {
+		t.Error("fallback resolved although the primary succeeded")
+	}
+}
+
+func TestOrElseBothFail(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	errA := errors.New("a failed")
+	errB := errors.New("b failed")
+	a := Provide(func(ctx *ResolveCtx) (int, error) { return 0, errA })
+	b := Provide(func(ctx *ResolveCtx) (int, error) { return 0, errB })
+
+	_, err := Resolve(scope, a.OrElse(b))
+
+	var fbErr *FallbackError
+	if !errors.As(err, &fbErr) {
+		t.Fatalf("expected FallbackError, got %v", err)
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("expected both branch errors, got %v", err)
+	}
+}
+
+func TestOptionalFailureCachedUntilInvalidation(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	var builds atomic.Int32
+	metrics := Provide(func(ctx *ResolveCtx) (string, error) {
+		builds.Add(1)
+		return "", errors.New("metrics backend unreachable")
+	})
+
+	var ctrl *Controller[string]
+	service := Derive1(metrics.Optional(), func(ctx *ResolveCtx, m *Controller[string]) (string, error) {
+		ctrl = m
+		return "service", nil
+	})
+	if _, err := Resolve(scope, service); err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, ok := ctrl.TryGet(); ok {
+			t.Fatal("expected the failed dependency to be absent")
+		}
+	}
+	if builds.Load() != 1 {
+		t.Errorf("expected the failure to be cached, got %d builds", builds.Load())
+	}
+
+	if err := Update(context.Background(), scope, metrics, "statsd"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if sink, ok := ctrl.TryGet(); !ok || sink != "statsd" {
+		t.Errorf("expected the updated value after invalidation, got %q, %v", sink, ok)
+	}
+}
+
+func TestOrElseSwitchesBackToPrimary(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	redis := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "", errors.New("redis down")
+	})
+	memory := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "memory", nil
+	})
+	cache := redis.OrElse(memory)
+
+	if got, _ := Resolve(scope, cache); got != "memory" {
+		t.Fatalf("expected fallback value, got %q", got)
+	}
+	if err := Update(context.Background(), scope, redis, "redis"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if got, _ := Resolve(scope, cache); got != "redis" {
+		t.Errorf("expected the updated primary, got %q", got)
+	}
+	if branch, _ := scope.FallbackBranch(cache); branch.UsedFallback {
+		t.Error("expected primary branch recorded after the update")
+	}
+}
+
+func TestOrElseBranchInOverlayStaysInOverlay(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	redis := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "redis", nil
+	})
+	memory := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "memory", nil
+	})
+	cache := redis.OrElse(memory)
+	if got, _ := Resolve(scope, cache); got != "redis" {
+		t.Fatalf("expected primary value, got %q", got)
+	}
+
+	failing := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "", errors.New("redis down")
+	})
+	flow := Flow1(cache, func(execCtx *ExecutionCtx, c *Controller[string]) (string, error) {
+		return c.Get()
+	})
+	got, _, err := Exec(scope, context.Background(), flow, WithExecPreset(redis, failing))
+	if err != nil || got != "memory" {
+		t.Fatalf("expected fallback inside the execution, got %q, %v", got, err)
+	}
+
+	branch, ok := scope.FallbackBranch(cache)
+	if !ok || branch.UsedFallback {
+		t.Errorf("expected the scope to keep its own primary branch, got %+v, %v", branch, ok)
+	}
+}
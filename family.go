@@ -0,0 +1,252 @@
+package pumped
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FamilyMemberInfo identifies the family and key an executor was built for
+type FamilyMemberInfo struct {
+	Family string
+	Key    any
+}
+
+var (
+	familyMemberTag = NewTag[FamilyMemberInfo]("family.member")
+	executorNameTag = NewTag[string]("executor.name")
+	familyTouchTag  = NewTag[func()]("family.touch")
+)
+
+// FamilyMember is the tag set on every executor built by a family
+func FamilyMember() Tag[FamilyMemberInfo] { return familyMemberTag }
+
+// Family is a keyed set of executors built from one factory, such as one
+// HTTP client per upstream or one pool per shard. Each key gets its own
+// executor, so values, cleanups and reactive invalidation are per key.
+type Family[K comparable, T any] struct {
+	name  string
+	now   func() time.Time
+	build func(K) *Executor[T]
+
+	mu      sync.Mutex
+	members map[K]*familyMember[T]
+}
+
+type familyMember[T any] struct {
+	exec     *Executor[T]
+	lastUsed time.Time
+}
+
+type familyConfig struct {
+	name       string
+	now        func() time.Time
+	memberOpts []ExecutorOption
+}
+
+// FamilyOption configures a family
+type FamilyOption func(*familyConfig)
+
+// WithFamilyName names the family. Members are named "name[key]" for graph
+// output.
+func WithFamilyName(name string) FamilyOption {
+	return func(cfg *familyConfig) {
+		cfg.name = name
+	}
+}
+
+// WithMemberOptions applies executor options, such as WithLifetime, to
+// every member
+func WithMemberOptions(opts ...ExecutorOption) FamilyOption {
+	return func(cfg *familyConfig) {
+		cfg.memberOpts = append(cfg.memberOpts, opts...)
+	}
+}
+
+// WithFamilyClock replaces time.Now for idle tracking, for tests
+func WithFamilyClock(now func() time.Time) FamilyOption {
+	return func(cfg *familyConfig) {
+		cfg.now = now
+	}
+}
+
+// NewFamily creates a family whose members have no dependencies
+func NewFamily[K comparable, T any](
+	factory func(*ResolveCtx, K) (T, error),
+	opts ...FamilyOption,
+) *Family[K, T] {
+	return newFamily(func(key K, execOpts []ExecutorOption) *Executor[T] {
+		return Provide(func(ctx *ResolveCtx) (T, error) {
+			return factory(ctx, key)
+		}, execOpts...)
+	}, opts)
+}
+
+// Family1 creates a family whose members depend on d1
+func Family1[K comparable, T any, D1 any](
+	d1 Dependency,
+	factory func(*ResolveCtx, K, *Controller[D1]) (T, error),
+	opts ...FamilyOption,
+) *Family[K, T] {
+	return newFamily(func(key K, execOpts []ExecutorOption) *Executor[T] {
+		return Derive1(d1, func(ctx *ResolveCtx, c1 *Controller[D1]) (T, error) {
+			return factory(ctx, key, c1)
+		}, execOpts...)
+	}, opts)
+}
+
+// Family2 creates a family whose members depend on d1 and d2
+func Family2[K comparable, T any, D1 any, D2 any](
+	d1 Dependency,
+	d2 Dependency,
+	factory func(*ResolveCtx, K, *Controller[D1], *Controller[D2]) (T, error),
+	opts ...FamilyOption,
+) *Family[K, T] {
+	return newFamily(func(key K, execOpts []ExecutorOption) *Executor[T] {
+		return Derive2(d1, d2, func(ctx *ResolveCtx, c1 *Controller[D1], c2 *Controller[D2]) (T, error) {
+			return factory(ctx, key, c1, c2)
+		}, execOpts...)
+	}, opts)
+}
+
+func newFamily[K comparable, T any](
+	create func(K, []ExecutorOption) *Executor[T],
+	opts []FamilyOption,
+) *Family[K, T] {
+	cfg := &familyConfig{now: time.Now}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	f := &Family[K, T]{
+		name:    cfg.name,
+		now:     cfg.now,
+		members: make(map[K]*familyMember[T]),
+	}
+	f.build = func(key K) *Executor[T] {
+		exec := create(key, cfg.memberOpts)
+		familyMemberTag.Set(exec, FamilyMemberInfo{Family: f.name, Key: key})
+		familyTouchTag.Set(exec, func() { f.touch(key, exec) })
+		if f.name != "" {
+			if _, named := executorNameTag.Get(exec); !named {
+				executorNameTag.Set(exec, fmt.Sprintf("%s[%v]", f.name, key))
+			}
+		}
+		return exec
+	}
+	return f
+}
+
+// Get returns the executor for key, creating it on first use. Repeated calls
+// return the same executor, so its value is cached per scope like any
+// other executor.
+func (f *Family[K, T]) Get(key K) *Executor[T] {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m, ok := f.members[key]
+	if !ok {
+		m = &familyMember[T]{exec: f.build(key)}
+		f.members[key] = m
+	}
+	m.lastUsed = f.now()
+	return m.exec
+}
+
+// touch marks the member for key as used, unless exec has been evicted
+func (f *Family[K, T]) touch(key K, exec *Executor[T]) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if m, ok := f.members[key]; ok && m.exec == exec {
+		m.lastUsed = f.now()
+	}
+}
+
+// touchMember records a resolution of a family member for EvictIdle
+func touchMember(exec AnyExecutor) {
+	if touch, ok := exec.GetTag(familyTouchTag); ok {
+		touch.(func())()
+	}
+}
+
+// Has reports whether key currently has a member
+func (f *Family[K, T]) Has(key K) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.members[key]
+	return ok
+}
+
+// Members returns the current members by key
+func (f *Family[K, T]) Members() map[K]*Executor[T] {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	members := make(map[K]*Executor[T], len(f.members))
+	for key, m := range f.members {
+		members[key] = m.exec
+	}
+	return members
+}
+
+// Evict drops the member for key, running its cleanups in s and
+// invalidating its reactive dependents. The next Get builds a new member.
+// Other scopes that resolved the old member keep it until disposed.
+func (f *Family[K, T]) Evict(s *Scope, key K) bool {
+	f.mu.Lock()
+	m, ok := f.members[key]
+	delete(f.members, key)
+	f.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	s.evict(m.exec)
+	return true
+}
+
+// EvictIdle evicts members neither requested through Get nor resolved for
+// at least idle and returns their keys
+func (f *Family[K, T]) EvictIdle(s *Scope, idle time.Duration) []K {
+	cutoff := f.now().Add(-idle)
+
+	f.mu.Lock()
+	var keys []K
+	var execs []*Executor[T]
+	for key, m := range f.members {
+		if m.lastUsed.After(cutoff) {
+			continue
+		}
+		keys = append(keys, key)
+		execs = append(execs, m.exec)
+		delete(f.members, key)
+	}
+	f.mu.Unlock()
+
+	for _, exec := range execs {
+		s.evict(exec)
+	}
+	return keys
+}
+
+// evict releases exec and its reactive dependents, running their cleanups
+func (s *Scope) evict(exec AnyExecutor) {
+	owner := s.owner(exec)
+
+	owner.mu.Lock()
+	dependents := owner.findReactiveDependents(exec)
+	owner.mu.Unlock()
+
+	for _, target := range append([]AnyExecutor{exec}, dependents...) {
+		owner.cleanupExecutor(target)
+		owner.cache.Delete(target)
+		owner.failures.Delete(target)
+		owner.publishExecutor(EventExecutorInvalidated, target)
+	}
+
+	// Drop the evicted executor from the graph so it can be collected. The
+	// invalidated dependents add their edges back when they resolve again.
+	owner.graph.RemoveExecutor(exec)
+}
@@ -0,0 +1,167 @@
+package pumped
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFamilyMembersPerKey(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	var builds atomic.Int32
+	clients := NewFamily(func(ctx *ResolveCtx, upstream string) (string, error) {
+		builds.Add(1)
+		return "client:" + upstream, nil
+	}, WithFamilyName("clients"))
+
+	if clients.Get("billing") != clients.Get("billing") {
+		t.Fatal("expected Get to memoize the executor per key")
+	}
+
+	billing, err := Resolve(scope, clients.Get("billing"))
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	search, _ := Resolve(scope, clients.Get("search"))
+	_, _ = Resolve(scope, clients.Get("billing"))
+
+	if billing != "client:billing" || search != "client:search" {
+		t.Errorf("unexpected values %q, %q", billing, search)
+	}
+	if builds.Load() != 2 {
+		t.Errorf("expected one build per key, got %d", builds.Load())
+	}
+
+	member, ok := FamilyMember().Get(clients.Get("search"))
+	if !ok || member.Family != "clients" || member.Key != "search" {
+		t.Errorf("unexpected member tag %+v", member)
+	}
+	if name, _ := executorNameTag.Get(clients.Get("search")); name != "clients[search]" {
+		t.Errorf("expected member name clients[search], got %q", name)
+	}
+}
+
+func TestFamilyReactiveInvalidation(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	dsn := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "v1", nil
+	})
+
+	pools := Family1(dsn.Reactive(), func(ctx *ResolveCtx, shard int, d *Controller[string]) (string, error) {
+		v, err := d.Get()
+		return v + "/" + string(rune('a'+shard)), err
+	})
+
+	first, _ := Resolve(scope, pools.Get(0))
+	if first != "v1/a" {
+		t.Fatalf("unexpected value %q", first)
+	}
+
+	if err := Update(context.Background(), scope, dsn, "v2"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	second, _ := Resolve(scope, pools.Get(0))
+	if second != "v2/a" {
+		t.Errorf("expected member to re-resolve after update, got %q", second)
+	}
+}
+
+func TestFamilyEvictIdle(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	now := time.Unix(0, 0)
+	var closed atomic.Int32
+	pools := NewFamily(func(ctx *ResolveCtx, shard int) (int, error) {
+		ctx.OnCleanup(func() error {
+			closed.Add(1)
+			return nil
+		})
+		return shard, nil
+	}, WithFamilyClock(func() time.Time { return now }))
+
+	_, _ = Resolve(scope, pools.Get(1))
+	now = now.Add(time.Minute)
+	_, _ = Resolve(scope, pools.Get(2))
+
+	evicted := pools.EvictIdle(scope, 30*time.Second)
+	if len(evicted) != 1 || evicted[0] != 1 {
+		t.Fatalf("expected shard 1 evicted, got %v", evicted)
+	}
+	if closed.Load() != 1 {
+		t.Errorf("expected evicted member cleanup to run, got %d", closed.Load())
+	}
+	if pools.Has(1) || !pools.Has(2) {
+		t.Errorf("unexpected members %v", pools.Members())
+	}
+
+	if !pools.Evict(scope, 2) || pools.Evict(scope, 2) {
+		t.Error("expected Evict to report whether the key had a member")
+	}
+	if closed.Load() != 2 {
+		t.Errorf("expected cleanup on explicit eviction, got %d", closed.Load())
+	}
+}
+
+func TestFamilyResolveKeepsMemberAlive(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	now := time.Unix(0, 0)
+	pools := NewFamily(func(ctx *ResolveCtx, shard int) (int, error) {
+		return shard, nil
+	}, WithFamilyClock(func() time.Time { return now }))
+
+	// A dependent holds the member and keeps resolving it without Get
+	member := pools.Get(1)
+	now = now.Add(time.Minute)
+	if _, err := Resolve(scope, member); err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+
+	if evicted := pools.EvictIdle(scope, 30*time.Second); len(evicted) != 0 {
+		t.Errorf("expected a recently resolved member to stay, evicted %v", evicted)
+	}
+}
+
+func TestFamilyEvictRemovesGraphEdges(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	base := Provide(func(ctx *ResolveCtx) (int, error) {
+		return 10, nil
+	})
+	pools := Family1(base.Reactive(), func(ctx *ResolveCtx, shard int, b *Controller[int]) (int, error) {
+		v, err := b.Get()
+		return v + shard, err
+	})
+	member := pools.Get(1)
+	user := Derive1(member.Reactive(), func(ctx *ResolveCtx, m *Controller[int]) (int, error) {
+		return m.Get()
+	})
+
+	if _, err := Resolve(scope, user); err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	pools.Evict(scope, 1)
+
+	for dependency, dependents := range scope.graph.ExportAllDependencies() {
+		if dependency == member {
+			t.Error("expected the evicted member to have no dependents in the graph")
+		}
+		for _, d := range dependents {
+			if d == member {
+				t.Error("expected the evicted member to be dropped as a dependent")
+			}
+		}
+	}
+	if _, ok := scope.graph.upstream[member]; ok {
+		t.Error("expected no upstream edges for the evicted member")
+	}
+}
@@ -45,13 +45,47 @@ type ExecutionCtx struct {
 	scope  *Scope
 	data   map[any]any
 	ctx    context.Context
+	dataMu sync.RWMutex
+
+	cleanupMu      sync.Mutex
+	cleanups       []cleanupEntry
+	cleanupsClosed bool
+	activeRuns     int
+	onDone         []func()
+
+	resolveMu sync.Mutex
+	resolved  map[AnyExecutor]*executionEntry
+
+	nodeMu      sync.Mutex
+	node        *ExecutionNode
+	late        map[any]any
+	lateSettled bool
+	lateValue   any
+	lateErr     error
+	lateHooks   []func(any, error)
+}
+
+func newExecutionCtx(s *Scope, parent *ExecutionCtx, ctx context.Context) *ExecutionCtx {
+	return &ExecutionCtx{
+		id:     s.generateExecutionID(),
+		parent: parent,
+		scope:  s,
+		data:   make(map[any]any),
+		ctx:    ctx,
+	}
 }
 
+// Set stores a value on this execution. It is safe to call from goroutines
+// spawned by the flow.
 func (e *ExecutionCtx) Set(tag any, value any) {
+	e.dataMu.Lock()
+	defer e.dataMu.Unlock()
 	e.data[tag] = value
 }
 
 func (e *ExecutionCtx) Get(tag any) (any, bool) {
+	e.dataMu.RLock()
+	defer e.dataMu.RUnlock()
 	v, ok := e.data[tag]
 	return v, ok
 }
@@ -59,7 +93,7 @@ func (e *ExecutionCtx) Get(tag any) (any, bool) {
 func (e *ExecutionCtx) GetFromParent(tag any) (any, bool) {
 	current := e.parent
 	for current != nil {
-		if v, ok := current.data[tag]; ok {
+		if v, ok := current.Get(tag); ok {
 			return v, true
 		}
 		current = current.parent
@@ -81,181 +115,366 @@ func (e *ExecutionCtx) Lookup(tag any) (any, bool) {
 	return e.GetFromScope(tag)
 }
 
+// ID returns the execution ID, which is also the ID of its ExecutionNode
+func (e *ExecutionCtx) ID() string {
+	return e.id
+}
+
+// HasExecPresets reports whether the execution runs with WithExecPreset
+// overrides, its own or a parent execution's. Extensions that share results
+// between executions should not share these.
+func (e *ExecutionCtx) HasExecPresets() bool {
+	return e.scope.parent != nil
+}
+
+// OnLateResult registers fn to receive the outcome of a factory that was
+// abandoned on cancellation, once it finally returns. fn runs immediately
+// when that already happened and never runs for executions that were not
+// abandoned. A late panic is reported as an error.
+func (e *ExecutionCtx) OnLateResult(fn func(value any, err error)) {
+	e.nodeMu.Lock()
+	if !e.lateSettled {
+		e.lateHooks = append(e.lateHooks, fn)
+		e.nodeMu.Unlock()
+		return
+	}
+	value, err := e.lateValue, e.lateErr
+	e.nodeMu.Unlock()
+	fn(value, err)
+}
+
 func (e *ExecutionCtx) Context() context.Context {
+	e.dataMu.RLock()
+	defer e.dataMu.RUnlock()
 	return e.ctx
 }
 
-func (e *ExecutionCtx) finalize() *ExecutionNode {
-	parentID := ""
-	if e.parent != nil {
-		parentID = e.parent.id
-	}
+// SetContext replaces the context the flow factory runs under. Middleware
+// and extensions wrapping OpExec use it to derive deadlines or values for
+// the rest of the chain.
+func (e *ExecutionCtx) SetContext(ctx context.Context) {
+	e.dataMu.Lock()
+	defer e.dataMu.Unlock()
+	e.ctx = ctx
+}
 
-	node := &ExecutionNode{
-		ID:       e.id,
-		ParentID: parentID,
-		Tags:     make(map[any]any),
+// GetValue retrieves a typed value stored on this execution. It returns
+// ErrTagNotFound when the tag is absent and a *TagTypeError when the stored
+// value has a different type.
+func GetValue[T any](e *ExecutionCtx, tag Tag[T]) (T, error) {
+	val, ok := e.Get(tag)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("tag %q: %w", tag.key, ErrTagNotFound)
 	}
+	return typedTagValue(tag, val)
+}
 
-	for k, v := range e.data {
-		node.Tags[k] = v
-	}
+// SetValue stores a typed value on this execution
+func SetValue[T any](e *ExecutionCtx, tag Tag[T], val T) {
+	e.Set(tag, val)
+}
 
-	return node
+// LookupValue retrieves a typed value from this execution, its parents or
+// the scope, in that order, like Lookup
+func LookupValue[T any](e *ExecutionCtx, tag Tag[T]) (T, error) {
+	val, ok := e.Lookup(tag)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("tag %q: %w", tag.key, ErrTagNotFound)
+	}
+	return typedTagValue(tag, val)
 }
 
-type ExecutionNode struct {
-	ID       string
-	ParentID string
-	Tags     map[any]any
+func typedTagValue[T any](tag Tag[T], val any) (T, error) {
+	typed, err := SafeTypeAssertion[T](val)
+	if err != nil {
+		return typed, &TagTypeError{Key: tag.key, Expected: fmt.Sprintf("%T", *new(T)), Actual: fmt.Sprintf("%T", val)}
+	}
+	return typed, nil
 }
 
-func (n *ExecutionNode) GetTag(tag any) (any, bool) {
-	v, ok := n.Tags[tag]
-	return v, ok
+// OnCleanup registers a cleanup function owned by this execution.
+// Cleanups run in LIFO order each time the flow factory returns, whether it
+// succeeded, failed, panicked or was cancelled, so a factory retried by
+// middleware starts with none pending. A cleanup registered after the
+// execution has ended runs immediately.
+func (e *ExecutionCtx) OnCleanup(fn func() error) {
+	e.cleanupMu.Lock()
+	if e.cleanupsClosed && e.activeRuns == 0 {
+		e.cleanupMu.Unlock()
+		e.runCleanup(fn)
+		return
+	}
+
+	e.cleanups = append(e.cleanups, cleanupEntry{
+		fn:    fn,
+		order: len(e.cleanups),
+	})
+	e.cleanupMu.Unlock()
 }
 
-func (n *ExecutionNode) GetAllTags() map[any]any {
-	return n.Tags
+// whenDone registers fn to run once the execution has ended and no factory
+// goroutine is still running for it.
+func (e *ExecutionCtx) whenDone(fn func()) {
+	e.cleanupMu.Lock()
+	e.onDone = append(e.onDone, fn)
+	e.cleanupMu.Unlock()
 }
 
-type ExecutionTree struct {
-	mu       sync.RWMutex
-	nodes    map[string]*ExecutionNode
-	byParent map[string][]string
-	roots    []string
-	limit    int
+func (e *ExecutionCtx) beginRun() {
+	e.cleanupMu.Lock()
+	e.activeRuns++
+	e.cleanupMu.Unlock()
 }
 
-func newExecutionTree(limit int) *ExecutionTree {
-	return &ExecutionTree{
-		nodes:    make(map[string]*ExecutionNode),
-		byParent: make(map[string][]string),
-		roots:    []string{},
-		limit:    limit,
-	}
+func (e *ExecutionCtx) endRun() {
+	e.cleanupMu.Lock()
+	e.activeRuns--
+	e.cleanupMu.Unlock()
+
+	e.runCleanups()
+	e.runDone()
 }
 
-func (t *ExecutionTree) addNode(node *ExecutionNode) {
-	t.mu.Lock()
-	defer t.mu.Unlock()
+// runCleanups runs the pending cleanups and forgets the execution-scoped
+// values they release, so a retried factory builds them again
+func (e *ExecutionCtx) runCleanups() {
+	e.cleanupMu.Lock()
+	entries := e.cleanups
+	e.cleanups = nil
+	e.cleanupMu.Unlock()
 
-	t.nodes[node.ID] = node
+	e.resolveMu.Lock()
+	e.resolved = nil
+	e.resolveMu.Unlock()
 
-	if node.ParentID == "" {
-		t.roots = append(t.roots, node.ID)
-	} else {
-		t.byParent[node.ParentID] = append(t.byParent[node.ParentID], node.ID)
+	for i := len(entries) - 1; i >= 0; i-- {
+		e.runCleanup(entries[i].fn)
 	}
+}
+
+// closeCleanups marks the execution as ended. Pending cleanups run now
+// unless a factory is still running, in which case they run when it returns.
+func (e *ExecutionCtx) closeCleanups() {
+	e.cleanupMu.Lock()
+	e.cleanupsClosed = true
+	active := e.activeRuns
+	e.cleanupMu.Unlock()
 
-	if len(t.nodes) > t.limit {
-		t.evictOldest()
+	if active > 0 {
+		return
 	}
+	e.runCleanups()
+	e.runDone()
 }
 
-func (t *ExecutionTree) evictOldest() {
-	if len(t.roots) == 0 {
+func (e *ExecutionCtx) runDone() {
+	e.cleanupMu.Lock()
+	if !e.cleanupsClosed || e.activeRuns > 0 {
+		e.cleanupMu.Unlock()
 		return
 	}
+	fns := e.onDone
+	e.onDone = nil
+	e.cleanupMu.Unlock()
 
-	oldestRoot := t.roots[0]
-	t.roots = t.roots[1:]
-
-	t.removeSubtree(oldestRoot)
+	for i := len(fns) - 1; i >= 0; i-- {
+		fns[i]()
+	}
 }
 
-func (t *ExecutionTree) removeSubtree(nodeID string) {
-	delete(t.nodes, nodeID)
-
-	children := t.byParent[nodeID]
-	delete(t.byParent, nodeID)
-
-	for _, childID := range children {
-		t.removeSubtree(childID)
+func (e *ExecutionCtx) runCleanup(fn func() error) {
+	if err := fn(); err != nil {
+		e.scope.handleCleanupError(&CleanupError{
+			ExecutionID: e.id,
+			Err:         err,
+			Context:     "flow",
+		})
 	}
 }
 
-func (t *ExecutionTree) GetNode(id string) *ExecutionNode {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-	return t.nodes[id]
-}
+// ResolveInExecution resolves an executor whose lifetime is bound to the
+// execution instead of the scope. The value is built once per factory run,
+// its cleanups run when the factory returns, and it is never stored in the
+// scope cache. A factory retried by middleware gets a fresh value. Dependencies of the executor still resolve through the scope.
+// Concurrent callers for the same executor wait for one factory call; a
+// failed call is not remembered.
+func ResolveInExecution[T any](e *ExecutionCtx, exec *Executor[T]) (T, error) {
+	var zero T
 
-func (t *ExecutionTree) GetChildren(id string) []*ExecutionNode {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+	if _, hasPreset := e.scope.lookupPreset(exec); hasPreset {
+		return Resolve(e.scope, exec)
+	}
 
-	childIDs := t.byParent[id]
-	children := make([]*ExecutionNode, 0, len(childIDs))
-	for _, childID := range childIDs {
-		if node := t.nodes[childID]; node != nil {
-			children = append(children, node)
+	e.resolveMu.Lock()
+	if entry, ok := e.resolved[exec]; ok {
+		e.resolveMu.Unlock()
+		<-entry.done
+		if entry.err != nil {
+			return zero, entry.err
 		}
+		typedVal, err := SafeTypeAssertion[T](entry.val)
+		if err != nil {
+			return zero, e.scope.resolveError(exec, err, "execution_cache_retrieval")
+		}
+		return typedVal, nil
 	}
-	return children
-}
+	entry := &executionEntry{done: make(chan struct{})}
+	if e.resolved == nil {
+		e.resolved = make(map[AnyExecutor]*executionEntry)
+	}
+	e.resolved[exec] = entry
+	e.resolveMu.Unlock()
 
-func (t *ExecutionTree) GetRoots() []*ExecutionNode {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+	val, err := resolveExecutionScoped(e, exec)
 
-	roots := make([]*ExecutionNode, 0, len(t.roots))
-	for _, rootID := range t.roots {
-		if node := t.nodes[rootID]; node != nil {
-			roots = append(roots, node)
+	entry.val, entry.err = val, err
+	if err != nil {
+		e.resolveMu.Lock()
+		if e.resolved[exec] == entry {
+			delete(e.resolved, exec)
 		}
+		e.resolveMu.Unlock()
 	}
-	return roots
+	close(entry.done)
+
+	return val, err
+}
+
+// executionEntry is an execution-scoped value, or the factory call building it
+type executionEntry struct {
+	done chan struct{}
+	val  any
+	err  error
 }
 
-func (t *ExecutionTree) Filter(predicate func(*ExecutionNode) bool) []*ExecutionNode {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+func resolveExecutionScoped[T any](e *ExecutionCtx, exec *Executor[T]) (T, error) {
+	var zero T
 
-	var result []*ExecutionNode
-	for _, node := range t.nodes {
-		if predicate(node) {
-			result = append(result, node)
-		}
+	resolveCtx := &ResolveCtx{
+		scope:      e.scope,
+		executorID: exec,
+		execCtx:    e,
+	}
+
+	val, err := exec.runFactory(e.scope, resolveCtx)
+	if err != nil {
+		return zero, e.scope.resolveError(exec, err, "factory")
+	}
+	decorated, err := e.scope.decorate(exec, val, resolveCtx)
+	if err != nil {
+		return zero, err
 	}
-	return result
+	val, err = SafeTypeAssertion[T](decorated)
+	if err != nil {
+		return zero, e.scope.resolveError(exec, err, "resolution_type_assertion")
+	}
+	return val, nil
 }
 
-func (t *ExecutionTree) Walk(rootID string, visitor func(*ExecutionNode) bool) {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+func (e *ExecutionCtx) finalize() *ExecutionNode {
+	e.closeCleanups()
+	e.publishEnd()
 
-	node := t.nodes[rootID]
-	if node == nil {
-		return
+	parentID := ""
+	if e.parent != nil {
+		parentID = e.parent.id
 	}
 
-	if !visitor(node) {
-		return
+	node := &ExecutionNode{
+		ID:       e.id,
+		ParentID: parentID,
+		Tags:     make(map[any]any),
+	}
+
+	e.dataMu.RLock()
+	for k, v := range e.data {
+		node.Tags[k] = v
 	}
+	e.dataMu.RUnlock()
 
-	for _, childID := range t.byParent[rootID] {
-		t.walkUnlocked(childID, visitor)
+	e.nodeMu.Lock()
+	for k, v := range e.late {
+		node.Tags[k] = v
 	}
+	e.node = node
+	e.nodeMu.Unlock()
+
+	return node
 }
 
-func (t *ExecutionTree) walkUnlocked(nodeID string, visitor func(*ExecutionNode) bool) {
-	node := t.nodes[nodeID]
-	if node == nil {
-		return
+type ExecutionNode struct {
+	ID       string
+	ParentID string
+	// Tags holds the tags recorded when the execution ended and is not
+	// modified afterwards. Outcomes of abandoned factories that return
+	// later (FinishedTime, LateOutput, LateError) are only visible through
+	// GetTag, GetAllTags and Snapshot.
+	Tags map[any]any
+
+	mu   sync.RWMutex
+	late map[any]any
+
+	recordedAt time.Time
+	seq        uint64
+}
+
+func (n *ExecutionNode) GetTag(tag any) (any, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if v, ok := n.late[tag]; ok {
+		return v, true
 	}
+	v, ok := n.Tags[tag]
+	return v, ok
+}
 
-	if !visitor(node) {
-		return
+// GetAllTags returns a copy of the node's tags, including late outcomes
+func (n *ExecutionNode) GetAllTags() map[any]any {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	tags := make(map[any]any, len(n.Tags)+len(n.late))
+	for k, v := range n.Tags {
+		tags[k] = v
+	}
+	for k, v := range n.late {
+		tags[k] = v
 	}
+	return tags
+}
 
-	for _, childID := range t.byParent[nodeID] {
-		t.walkUnlocked(childID, visitor)
+// setLateTags records the outcome of an abandoned factory beside Tags
+func (n *ExecutionNode) setLateTags(tags map[any]any) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.late == nil {
+		n.late = make(map[any]any, len(tags))
+	}
+	for k, v := range tags {
+		n.late[k] = v
 	}
 }
 
 type FlowOption func(*flowConfig)
 
+// FlowMiddleware wraps a single flow's execution. Calling next runs the rest
+// of the chain and the factory; a middleware may call it several times,
+// not at all, or replace its result.
+type FlowMiddleware func(execCtx *ExecutionCtx, next func() (any, error)) (any, error)
+
+// WithFlowMiddleware returns an option that attaches middleware to a flow.
+// Middleware runs inside extension Wrap hooks, the first one outermost.
+func WithFlowMiddleware(mw ...FlowMiddleware) FlowOption {
+	return func(cfg *flowConfig) {
+		existing, _ := cfg.tags[flowMiddlewareTag].([]FlowMiddleware)
+		combined := make([]FlowMiddleware, 0, len(existing)+len(mw))
+		combined = append(combined, existing...)
+		cfg.tags[flowMiddlewareTag] = append(combined, mw...)
+	}
+}
+
 type flowConfig struct {
 	tags map[any]any
 }
@@ -282,8 +501,28 @@ const (
 	ExecutionStatusSuccess
 	ExecutionStatusFailed
 	ExecutionStatusCancelled
+	// ExecutionStatusRejected marks executions refused before the factory
+	// ran, e.g. by a rate limit; see ErrRejected
+	ExecutionStatusRejected
 )
 
+func (s ExecutionStatus) String() string {
+	switch s {
+	case ExecutionStatusRunning:
+		return "running"
+	case ExecutionStatusSuccess:
+		return "success"
+	case ExecutionStatusFailed:
+		return "failed"
+	case ExecutionStatusCancelled:
+		return "cancelled"
+	case ExecutionStatusRejected:
+		return "rejected"
+	default:
+		return fmt.Sprintf("status(%d)", int(s))
+	}
+}
+
 var (
 	flowNameTag   = NewTag[string]("flow.name")
 	timeoutTag    = NewTag[time.Duration]("flow.timeout")
@@ -298,6 +537,14 @@ var (
 	cachedTag     = NewTag[any]("exec.cached_output")
 	skipExecTag   = NewTag[bool]("exec.skip")
 	panicStackTag = NewTag[[]byte]("exec.panic_stack")
+
+	flowMiddlewareTag = NewTag[[]FlowMiddleware]("flow.middleware")
+
+	gracePeriodTag  = NewTag[time.Duration]("flow.grace_period")
+	abandonedTag    = NewTag[bool]("exec.abandoned")
+	finishedTimeTag = NewTag[time.Time]("exec.finished_time")
+	lateOutputTag   = NewTag[any]("exec.late_output")
+	lateErrorTag    = NewTag[error]("exec.late_error")
 )
 
 func FlowName() Tag[string]        { return flowNameTag }
@@ -314,45 +561,55 @@ func CachedOutput() Tag[any]       { return cachedTag }
 func SkipExecution() Tag[bool]     { return skipExecTag }
 func PanicStack() Tag[[]byte]      { return panicStackTag }
 
+// GracePeriod overrides the scope's cancellation grace period for a flow
+func GracePeriod() Tag[time.Duration] { return gracePeriodTag }
+
+// Abandoned is set on executions whose factory was still running when the
+// caller returned after cancellation
+func Abandoned() Tag[bool] { return abandonedTag }
+
+// FinishedTime records when an abandoned factory actually returned
+func FinishedTime() Tag[time.Time] { return finishedTimeTag }
+
+// LateOutput holds the result an abandoned factory produced after cancellation
+func LateOutput() Tag[any] { return lateOutputTag }
+
+// LateError holds the error an abandoned factory returned after cancellation
+func LateError() Tag[error] { return lateErrorTag }
+
 func Exec1[R any](e *ExecutionCtx, flow *Flow[R]) (R, *ExecutionCtx, error) {
 	var zero R
 
 	// Check for cancellation before resolving dependencies
 	select {
-	case <-e.ctx.Done():
+	case <-e.Context().Done():
 		e.Set(endTimeTag, time.Now())
 		e.Set(statusTag, ExecutionStatusCancelled)
-		e.Set(errorTag, e.ctx.Err())
-		return zero, nil, e.ctx.Err()
+		e.Set(errorTag, e.Context().Err())
+		return zero, nil, e.Context().Err()
 	default:
 	}
 
 	for _, dep := range flow.deps {
-		if dep.GetMode() == ModeLazy {
+		if dep.GetMode() == ModeLazy || LifetimeOf(dep.GetExecutor()) != Singleton {
 			continue
 		}
 		// Check for cancellation before each dependency resolution
 		select {
-		case <-e.ctx.Done():
+		case <-e.Context().Done():
 			e.Set(endTimeTag, time.Now())
 			e.Set(statusTag, ExecutionStatusCancelled)
-			e.Set(errorTag, e.ctx.Err())
-			return zero, nil, e.ctx.Err()
+			e.Set(errorTag, e.Context().Err())
+			return zero, nil, e.Context().Err()
 		default:
 		}
-		_, err := dep.GetExecutor().ResolveAny(e.scope)
-		if err != nil {
+		_, err := resolveDeclared(e.scope, dep)
+		if err != nil && dep.GetMode() != ModeOptional {
 			return zero, nil, fmt.Errorf("resolving dependency: %w", err)
 		}
 	}
 
-	childCtx := &ExecutionCtx{
-		id:     e.scope.generateExecutionID(),
-		parent: e,
-		scope:  e.scope,
-		data:   make(map[any]any),
-		ctx:    e.ctx,
-	}
+	childCtx := newExecutionCtx(e.scope, e, e.Context())
 
 	if name, ok := flow.GetTag(flowNameTag); ok {
 		childCtx.Set(flowNameTag, name)
@@ -370,61 +627,35 @@ func Exec1[R any](e *ExecutionCtx, flow *Flow[R]) (R, *ExecutionCtx, error) {
 		if err := ext.OnFlowStart(childCtx, flow); err != nil {
 			childCtx.Set(statusTag, ExecutionStatusFailed)
 			childCtx.Set(errorTag, err)
+			childCtx.abort()
 			return zero, childCtx, err
 		}
 	}
 
+	childCtx.publish(EventFlowStarted)
+
 	// Check for cancellation before executing the flow
 	select {
-	case <-childCtx.ctx.Done():
+	case <-childCtx.Context().Done():
 		childCtx.Set(endTimeTag, time.Now())
 		childCtx.Set(statusTag, ExecutionStatusCancelled)
-		childCtx.Set(errorTag, childCtx.ctx.Err())
-		return zero, childCtx, childCtx.ctx.Err()
+		childCtx.Set(errorTag, childCtx.Context().Err())
+		childCtx.abort()
+		return zero, childCtx, childCtx.Context().Err()
 	default:
 	}
 
-	if skip, ok := childCtx.Get(skipExecTag); ok && skip.(bool) {
-		// Check for cancellation even in skip case
-		select {
-		case <-childCtx.ctx.Done():
-			childCtx.Set(endTimeTag, time.Now())
-			childCtx.Set(statusTag, ExecutionStatusCancelled)
-			childCtx.Set(errorTag, childCtx.ctx.Err())
-			return zero, childCtx, childCtx.ctx.Err()
-		default:
-		}
-
-		if cached, ok := childCtx.Get(cachedTag); ok {
-			childCtx.Set(endTimeTag, time.Now())
-			childCtx.Set(statusTag, ExecutionStatusSuccess)
-			childCtx.Set(outputTag, cached)
-
-			for i := len(exts) - 1; i >= 0; i-- {
-				if err := exts[i].OnFlowEnd(childCtx, cached, nil); err != nil {
-					childCtx.Set(statusTag, ExecutionStatusFailed)
-					childCtx.Set(errorTag, err)
-					return zero, childCtx, err
-				}
-			}
-
-			node := childCtx.finalize()
-			e.scope.execTree.addNode(node)
-
-			return cached.(R), childCtx, nil
-		}
+	if result, skipped, err := skipExecution[R](childCtx, exts); skipped {
+		return result, childCtx, err
 	}
 
-	result, err := executeFlow(childCtx, flow)
+	result, err := wrapExec(childCtx, flow, exts, func() (R, error) {
+		return executeFlow(childCtx, flow)
+	})
 
 	childCtx.Set(endTimeTag, time.Now())
 	if err != nil {
-		// Check if this is a cancellation error
-		if errors.Is(err, context.Canceled) {
-			childCtx.Set(statusTag, ExecutionStatusCancelled)
-		} else {
-			childCtx.Set(statusTag, ExecutionStatusFailed)
-		}
+		childCtx.Set(statusTag, statusForError(err))
 		childCtx.Set(errorTag, err)
 	} else {
 		childCtx.Set(statusTag, ExecutionStatusSuccess)
@@ -443,6 +674,111 @@ func Exec1[R any](e *ExecutionCtx, flow *Flow[R]) (R, *ExecutionCtx, error) {
 	return result, childCtx, err
 }
 
+// skipExecution completes an execution from CachedOutput when an extension
+// set SkipExecution during OnFlowStart. It reports false when the flow has
+// to run.
+func skipExecution[R any](e *ExecutionCtx, exts []Extension) (R, bool, error) {
+	var zero R
+
+	if skip, ok := e.Get(skipExecTag); !ok || skip != true {
+		return zero, false, nil
+	}
+	cached, ok := e.Get(cachedTag)
+	if !ok {
+		return zero, false, nil
+	}
+
+	// Check for cancellation even in skip case
+	select {
+	case <-e.Context().Done():
+		e.Set(endTimeTag, time.Now())
+		e.Set(statusTag, ExecutionStatusCancelled)
+		e.Set(errorTag, e.Context().Err())
+		e.abort()
+		return zero, true, e.Context().Err()
+	default:
+	}
+
+	result, err := SafeTypeAssertion[R](cached)
+	e.Set(endTimeTag, time.Now())
+	if err != nil {
+		e.Set(statusTag, ExecutionStatusFailed)
+		e.Set(errorTag, err)
+	} else {
+		e.Set(statusTag, ExecutionStatusSuccess)
+		e.Set(outputTag, cached)
+	}
+
+	for i := len(exts) - 1; i >= 0; i-- {
+		if extErr := exts[i].OnFlowEnd(e, result, err); extErr != nil && err == nil {
+			err = extErr
+			e.Set(statusTag, ExecutionStatusFailed)
+			e.Set(errorTag, err)
+		}
+	}
+
+	node := e.finalize()
+	e.scope.execTree.addNode(node)
+
+	return result, true, err
+}
+
+// statusForError returns the terminal status of an execution that failed with err
+func statusForError(err error) ExecutionStatus {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ExecutionStatusCancelled
+	case errors.Is(err, ErrRejected):
+		return ExecutionStatusRejected
+	default:
+		return ExecutionStatusFailed
+	}
+}
+
+// wrapExec runs a flow execution through extension Wrap hooks (as OpExec)
+// and the flow's own middleware
+func wrapExec[R any](e *ExecutionCtx, flow *Flow[R], exts []Extension, run func() (R, error)) (R, error) {
+	middleware, _ := flow.tags[flowMiddlewareTag].([]FlowMiddleware)
+	if len(exts) == 0 && len(middleware) == 0 {
+		return run()
+	}
+
+	op := &Operation{
+		Kind:         OpExec,
+		Scope:        e.scope,
+		ExecutionCtx: e,
+		Flow:         flow,
+	}
+
+	next := func() (any, error) {
+		return run()
+	}
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		mw := middleware[i]
+		currentNext := next
+		next = func() (any, error) {
+			return mw(e, currentNext)
+		}
+	}
+
+	for i := len(exts) - 1; i >= 0; i-- {
+		ext := exts[i]
+		currentNext := next
+		next = func() (any, error) {
+			return ext.Wrap(e.Context(), currentNext, op)
+		}
+	}
+
+	out, err := next()
+	result, typeErr := SafeTypeAssertion[R](out)
+	if typeErr != nil {
+		var zero R
+		return zero, errors.Join(err, fmt.Errorf("flow result: %w", typeErr))
+	}
+	return result, err
+}
+
 func executeFlow[R any](e *ExecutionCtx, flow *Flow[R]) (result R, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -466,17 +802,18 @@ func executeFlow[R any](e *ExecutionCtx, flow *Flow[R]) (result R, err error) {
 
 	// Check for cancellation before executing the factory
 	select {
-	case <-e.ctx.Done():
-		err = e.ctx.Err()
+	case <-e.Context().Done():
+		err = e.Context().Err()
 		e.Set(endTimeTag, time.Now())
 		e.Set(statusTag, ExecutionStatusCancelled)
-		e.Set(errorTag, e.ctx.Err())
+		e.Set(errorTag, e.Context().Err())
 		return
 	default:
 	}
 
 	resolveCtx := &ResolveCtx{
-		scope: e.scope,
+		scope:   e.scope,
+		execCtx: e,
 	}
 
 	// Execute factory with cancellation monitoring
@@ -487,55 +824,170 @@ func executeFlow[R any](e *ExecutionCtx, flow *Flow[R]) (result R, err error) {
 		stack []byte
 	}
 
+	run := &flowRun{}
+	root := e.scope.root()
+	root.flowsInFlight.Add(1)
+	e.beginRun()
+
 	resultCh := make(chan factoryResult, 1)
 	go func() {
+		var res factoryResult
+		defer func() {
+			root.flowsInFlight.Add(-1)
+			if run.finish() {
+				root.flowsAbandoned.Add(-1)
+				e.recordLateResult(res.value, res.err, res.panic, res.stack)
+			}
+			resultCh <- res
+		}()
 		defer func() {
 			if r := recover(); r != nil {
-				stack := debug.Stack()
-				resultCh <- factoryResult{
+				res = factoryResult{
 					panic: r,
-					stack: stack,
+					stack: debug.Stack(),
 				}
 			}
 		}()
+		// Flow-owned cleanups run as soon as the factory returns, before the
+		// result is handed back, so callers observe released resources.
+		defer e.endRun()
 
-		value, err := flow.factory(e, resolveCtx)
-		resultCh <- factoryResult{
-			value: value,
-			err:   err,
-		}
+		res.value, res.err = flow.factory(e, resolveCtx)
 	}()
 
-	select {
-	case res := <-resultCh:
-		if res.panic != nil {
-			// Panic occurred in factory
-			err = fmt.Errorf("panic in flow: %v", res.panic)
-			e.Set(panicStackTag, res.stack)
-			e.Set(errorTag, err)
+	// complete hands back the factory's outcome, routing a panic through
+	// OnFlowPanic
+	complete := func(res factoryResult) (R, error) {
+		if res.panic == nil {
+			return res.value, res.err
+		}
 
-			e.scope.mu.RLock()
-			exts := make([]Extension, len(e.scope.extensions))
-			copy(exts, e.scope.extensions)
-			e.scope.mu.RUnlock()
+		err := fmt.Errorf("panic in flow: %v", res.panic)
+		e.Set(panicStackTag, res.stack)
+		e.Set(errorTag, err)
 
-			for _, ext := range exts {
-				if onFlowPanicErr := ext.OnFlowPanic(e, res.panic, res.stack); onFlowPanicErr != nil {
-					err = errors.Join(err, onFlowPanicErr)
-				}
+		e.scope.mu.RLock()
+		exts := make([]Extension, len(e.scope.extensions))
+		copy(exts, e.scope.extensions)
+		e.scope.mu.RUnlock()
+
+		for _, ext := range exts {
+			if onFlowPanicErr := ext.OnFlowPanic(e, res.panic, res.stack); onFlowPanicErr != nil {
+				err = errors.Join(err, onFlowPanicErr)
 			}
-			return
 		}
-		// Factory completed normally
-		result = res.value
-		err = res.err
+		var zero R
+		return zero, err
+	}
+
+	select {
+	case res := <-resultCh:
+		result, err = complete(res)
 		return
-	case <-e.ctx.Done():
-		// Context was cancelled
-		err = e.ctx.Err()
+	case <-e.Context().Done():
+		// Context was cancelled; give the factory a chance to wind down
+		// before abandoning it. A factory that returns in time reports its
+		// own outcome.
+		if grace := e.gracePeriod(flow); grace > 0 {
+			timer := time.NewTimer(grace)
+			select {
+			case res := <-resultCh:
+				timer.Stop()
+				result, err = complete(res)
+				return
+			case <-timer.C:
+			}
+		}
+
+		if !run.abandon() {
+			// The factory finished after the grace period ran out
+			result, err = complete(<-resultCh)
+			return
+		}
+		root.flowsAbandoned.Add(1)
+		e.Set(abandonedTag, true)
+
+		err = e.Context().Err()
 		e.Set(endTimeTag, time.Now())
 		e.Set(statusTag, ExecutionStatusCancelled)
-		e.Set(errorTag, e.ctx.Err())
+		e.Set(errorTag, e.Context().Err())
 		return
 	}
 }
+
+// flowRun coordinates a factory goroutine with the caller that may give up
+// waiting for it.
+type flowRun struct {
+	mu        sync.Mutex
+	done      bool
+	abandoned bool
+}
+
+// abandon marks the run as abandoned unless the factory already finished.
+func (r *flowRun) abandon() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return false
+	}
+	r.abandoned = true
+	return true
+}
+
+// finish marks the run as done and reports whether it had been abandoned.
+func (r *flowRun) finish() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done = true
+	return r.abandoned
+}
+
+func (e *ExecutionCtx) gracePeriod(flow AnyFlow) time.Duration {
+	if val, ok := flow.GetTag(gracePeriodTag); ok {
+		return val.(time.Duration)
+	}
+	return e.scope.gracePeriod
+}
+
+// recordLateResult stores the outcome of an abandoned factory once it
+// finally returns. The values land on the execution node when it has
+// already been recorded in the tree.
+func (e *ExecutionCtx) recordLateResult(value any, err error, recovered any, stack []byte) {
+	late := map[any]any{
+		finishedTimeTag: time.Now(),
+	}
+	switch {
+	case recovered != nil:
+		late[lateErrorTag] = fmt.Errorf("panic in flow: %v", recovered)
+		late[panicStackTag] = stack
+	case err != nil:
+		late[lateErrorTag] = err
+	default:
+		late[lateOutputTag] = value
+	}
+
+	e.nodeMu.Lock()
+	e.lateSettled = true
+	e.lateValue = value
+	if err, ok := late[lateErrorTag].(error); ok {
+		e.lateErr = err
+	}
+	hooks, lateValue, lateErr := e.lateHooks, e.lateValue, e.lateErr
+	e.lateHooks = nil
+
+	if e.node != nil {
+		e.node.setLateTags(late)
+	} else {
+		if e.late == nil {
+			e.late = make(map[any]any)
+		}
+		for k, v := range late {
+			e.late[k] = v
+		}
+	}
+	e.nodeMu.Unlock()
+
+	for _, fn := range hooks {
+		fn(lateValue, lateErr)
+	}
+}
@@ -22,8 +22,9 @@ func Flow1[R, D1 any](
 		deps: []Dependency{d1},
 		factory: func(execCtx *ExecutionCtx, resolveCtx *ResolveCtx) (R, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    execCtx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     execCtx.scope,
+				requester: execCtx,
 			}
 			return factory(execCtx, ctrl1)
 		},
@@ -56,12 +57,14 @@ func Flow2[R, D1, D2 any](
 		deps: []Dependency{d1, d2},
 		factory: func(execCtx *ExecutionCtx, resolveCtx *ResolveCtx) (R, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    execCtx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     execCtx.scope,
+				requester: execCtx,
 			}
 			ctrl2 := &Controller[D2]{
-				executor: d2.GetExecutor().(*Executor[D2]),
-				scope:    execCtx.scope,
+				executor:  d2.GetExecutor().(*Executor[D2]),
+				scope:     execCtx.scope,
+				requester: execCtx,
 			}
 			return factory(execCtx, ctrl1, ctrl2)
 		},
@@ -97,16 +100,19 @@ func Flow3[R, D1, D2, D3 any](
 		deps: []Dependency{d1, d2, d3},
 		factory: func(execCtx *ExecutionCtx, resolveCtx *ResolveCtx) (R, error) {
 			ctrl1 := &Controller[D1]{
-				executor: d1.GetExecutor().(*Executor[D1]),
-				scope:    execCtx.scope,
+				executor:  d1.GetExecutor().(*Executor[D1]),
+				scope:     execCtx.scope,
+				requester: execCtx,
 			}
 			ctrl2 := &Controller[D2]{
-				executor: d2.GetExecutor().(*Executor[D2]),
-				scope:    execCtx.scope,
+				executor:  d2.GetExecutor().(*Executor[D2]),
+				scope:     execCtx.scope,
+				requester: execCtx,
 			}
 			ctrl3 := &Controller[D3]{
-				executor: d3.GetExecutor().(*Executor[D3]),
-				scope:    execCtx.scope,
+				executor:  d3.GetExecutor().(*Executor[D3]),
+				scope:     execCtx.scope,
+				requester: execCtx,
 			}
 			return factory(execCtx, ctrl1, ctrl2, ctrl3)
 		},
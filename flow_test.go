@@ -3,6 +3,9 @@ package pumped
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -327,3 +330,743 @@ func TestFlowCancellationDuringDependencyResolution(t *testing.T) {
 		t.Errorf("expected status Cancelled, got %v", status)
 	}
 }
+
+func TestFlowCleanupRunsOnCompletion(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	var cleaned []string
+	var mu sync.Mutex
+	record := func(name string) func() error {
+		return func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			cleaned = append(cleaned, name)
+			return nil
+		}
+	}
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	okFlow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		execCtx.OnCleanup(record("first"))
+		execCtx.OnCleanup(record("second"))
+		return "ok", nil
+	})
+
+	failFlow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		execCtx.OnCleanup(record("rollback"))
+		return "", errors.New("boom")
+	})
+
+	panicFlow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		execCtx.OnCleanup(record("panic"))
+		panic("kaboom")
+	})
+
+	if _, _, err := Exec(scope, context.Background(), okFlow); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := Exec(scope, context.Background(), failFlow); err == nil {
+		t.Fatal("expected error from failing flow")
+	}
+	if _, _, err := Exec(scope, context.Background(), panicFlow); err == nil {
+		t.Fatal("expected error from panicking flow")
+	}
+
+	expected := []string{"second", "first", "rollback", "panic"}
+	if len(cleaned) != len(expected) {
+		t.Fatalf("expected cleanups %v, got %v", expected, cleaned)
+	}
+	for i := range expected {
+		if cleaned[i] != expected[i] {
+			t.Errorf("cleanup %d: expected %q, got %q", i, expected[i], cleaned[i])
+		}
+	}
+}
+
+func TestFlowCleanupRunsAfterCancellation(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	release := make(chan struct{})
+	cleaned := make(chan struct{})
+
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		execCtx.OnCleanup(func() error {
+			close(cleaned)
+			return nil
+		})
+		cancel()
+		<-release
+		return "late", nil
+	})
+
+	_, _, err := Exec(scope, ctx, flow)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	close(release)
+
+	select {
+	case <-cleaned:
+	case <-time.After(time.Second):
+		t.Fatal("cleanup did not run after cancelled factory returned")
+	}
+}
+
+func TestFlowCleanupErrorReachesExtensions(t *testing.T) {
+	var captured *CleanupError
+	ext := &testCleanupExtension{
+		BaseExtension: NewBaseExtension("test"),
+		handler: func(err *CleanupError) bool {
+			captured = err
+			return true
+		},
+	}
+
+	scope := NewScope(WithExtension(ext))
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		execCtx.OnCleanup(func() error {
+			return errors.New("cleanup failed")
+		})
+		return 1, nil
+	})
+
+	_, execCtx, err := Exec(scope, context.Background(), flow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if captured == nil {
+		t.Fatal("cleanup error was not reported")
+	}
+	if captured.Context != "flow" {
+		t.Errorf("expected context 'flow', got %q", captured.Context)
+	}
+	if captured.ExecutionID != execCtx.id {
+		t.Errorf("expected execution ID %q, got %q", execCtx.id, captured.ExecutionID)
+	}
+}
+
+func TestResolveInExecution(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	var built, closed int
+	var mu sync.Mutex
+
+	prefix := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "tx", nil
+	})
+
+	tx := Derive1(prefix, func(ctx *ResolveCtx, p *Controller[string]) (string, error) {
+		val, err := p.Get()
+		if err != nil {
+			return "", err
+		}
+
+		mu.Lock()
+		built++
+		id := built
+		mu.Unlock()
+
+		ctx.OnCleanup(func() error {
+			mu.Lock()
+			closed++
+			mu.Unlock()
+			return nil
+		})
+		return fmt.Sprintf("%s-%d", val, id), nil
+	})
+
+	flow := Flow1(prefix, func(execCtx *ExecutionCtx, _ *Controller[string]) (string, error) {
+		first, err := ResolveInExecution(execCtx, tx)
+		if err != nil {
+			return "", err
+		}
+		second, err := ResolveInExecution(execCtx, tx)
+		if err != nil {
+			return "", err
+		}
+		if first != second {
+			return "", fmt.Errorf("expected same value within execution, got %q and %q", first, second)
+		}
+		return first, nil
+	})
+
+	r1, _, err := Exec(scope, context.Background(), flow)
+	if err != nil {
+		t.Fatalf("first execution failed: %v", err)
+	}
+	r2, _, err := Exec(scope, context.Background(), flow)
+	if err != nil {
+		t.Fatalf("second execution failed: %v", err)
+	}
+
+	if r1 == r2 {
+		t.Errorf("expected distinct values per execution, got %q twice", r1)
+	}
+	if built != 2 || closed != 2 {
+		t.Errorf("expected 2 builds and 2 cleanups, got %d and %d", built, closed)
+	}
+	if Accessor(scope, tx).IsCached() {
+		t.Error("execution-bound executor should not be cached on the scope")
+	}
+}
+
+func TestResolveInExecutionNested(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	var built atomic.Int32
+	conn := Provide(func(ctx *ResolveCtx) (string, error) {
+		built.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return "conn", nil
+	}, WithLifetime(Scoped))
+	tx := Derive1(conn, func(ctx *ResolveCtx, c *Controller[string]) (string, error) {
+		val, err := c.Get()
+		return val + "-tx", err
+	}, WithLifetime(Scoped))
+
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		var wg sync.WaitGroup
+		errs := make(chan error, 2)
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := ResolveInExecution(execCtx, conn); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			return "", err
+		}
+		return ResolveInExecution(execCtx, tx)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		result, _, err := Exec(scope, context.Background(), flow)
+		if err != nil || result != "conn-tx" {
+			t.Errorf("expected conn-tx, got %q, %v", result, err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("nested execution-scoped resolution deadlocked")
+	}
+	if built.Load() != 1 {
+		t.Errorf("expected one factory call per execution, got %d", built.Load())
+	}
+}
+
+func TestAbandonedFlowRecordsLateResult(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		cancel()
+		<-release
+		return "late", nil
+	}, WithFlowTag(FlowName(), "stubborn"))
+
+	_, execCtx, err := Exec(scope, ctx, flow)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if abandoned, _ := execCtx.Get(abandonedTag); abandoned != true {
+		t.Error("expected execution to be marked abandoned")
+	}
+
+	stats := scope.FlowStats()
+	if stats.InFlight != 1 || stats.Abandoned != 1 {
+		t.Errorf("expected 1 in-flight and 1 abandoned, got %+v", stats)
+	}
+
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for scope.FlowStats().InFlight != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("factory goroutine did not finish, stats %+v", scope.FlowStats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if stats := scope.FlowStats(); stats.Abandoned != 0 {
+		t.Errorf("expected no abandoned executions, got %+v", stats)
+	}
+
+	node := scope.GetExecutionTree().GetNode(execCtx.ID())
+	if node == nil {
+		t.Fatal("execution node not recorded")
+	}
+	if out, ok := node.GetTag(lateOutputTag); !ok || out != "late" {
+		t.Errorf("expected late output 'late', got %v", out)
+	}
+	if _, ok := node.GetTag(finishedTimeTag); !ok {
+		t.Error("expected finished time to be recorded")
+	}
+	if status, _ := node.GetTag(statusTag); status != ExecutionStatusCancelled {
+		t.Errorf("expected status Cancelled, got %v", status)
+	}
+}
+
+func TestCancellationGracePeriod(t *testing.T) {
+	scope := NewScope(WithCancellationGracePeriod(time.Second))
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		cancel()
+		time.Sleep(20 * time.Millisecond)
+		return "", execCtx.Context().Err()
+	})
+
+	_, execCtx, err := Exec(scope, ctx, flow)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if _, ok := execCtx.Get(abandonedTag); ok {
+		t.Error("factory finished within grace period and should not be abandoned")
+	}
+	if stats := scope.FlowStats(); stats.InFlight != 0 || stats.Abandoned != 0 {
+		t.Errorf("expected no running factories, got %+v", stats)
+	}
+
+	noGrace := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		time.Sleep(50 * time.Millisecond)
+		return "", nil
+	}, WithFlowTag(GracePeriod(), 0))
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	cancel2Later := time.AfterFunc(5*time.Millisecond, cancel2)
+	defer cancel2Later.Stop()
+
+	_, execCtx, _ = Exec(scope, ctx2, noGrace)
+	if abandoned, _ := execCtx.Get(abandonedTag); abandoned != true {
+		t.Error("flow-level grace period of 0 should abandon immediately")
+	}
+}
+
+type flowPanicRecorder struct {
+	BaseExtension
+	recovered any
+}
+
+func (r *flowPanicRecorder) OnFlowPanic(execCtx *ExecutionCtx, recovered any, stack []byte) error {
+	r.recovered = recovered
+	return nil
+}
+
+func TestGracePeriodKeepsOutcome(t *testing.T) {
+	recorder := &flowPanicRecorder{BaseExtension: NewBaseExtension("panic-recorder")}
+	scope := NewScope(WithCancellationGracePeriod(time.Second), WithExtension(recorder))
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	finishing := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		cancel()
+		time.Sleep(20 * time.Millisecond)
+		return "done", nil
+	})
+
+	result, execCtx, err := Exec(scope, ctx, finishing)
+	if err != nil || result != "done" {
+		t.Fatalf("expected the factory's result, got %q, %v", result, err)
+	}
+	if status, _ := execCtx.Get(statusTag); status != ExecutionStatusSuccess {
+		t.Errorf("expected status Success, got %v", status)
+	}
+	if output, _ := execCtx.Get(outputTag); output != "done" {
+		t.Errorf("expected output to be recorded, got %v", output)
+	}
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	panicking := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		cancel2()
+		time.Sleep(20 * time.Millisecond)
+		panic("late panic")
+	})
+
+	_, execCtx, err = Exec(scope, ctx2, panicking)
+	if err == nil || errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the panic error, got %v", err)
+	}
+	if recorder.recovered != "late panic" {
+		t.Errorf("expected OnFlowPanic for a panic in the grace period, got %v", recorder.recovered)
+	}
+	if _, ok := execCtx.Get(panicStackTag); !ok {
+		t.Error("expected panic stack to be recorded")
+	}
+}
+
+type retryExecExtension struct {
+	BaseExtension
+	attempts int
+	ops      []*Operation
+}
+
+func (e *retryExecExtension) Wrap(ctx context.Context, next func() (any, error), op *Operation) (any, error) {
+	if op.Kind != OpExec {
+		return next()
+	}
+	e.ops = append(e.ops, op)
+
+	var result any
+	var err error
+	for i := 0; i < e.attempts; i++ {
+		result, err = next()
+		if err == nil {
+			return result, nil
+		}
+	}
+	return result, err
+}
+
+func TestExecWrapRetriesFlow(t *testing.T) {
+	ext := &retryExecExtension{
+		BaseExtension: NewBaseExtension("retry"),
+		attempts:      3,
+	}
+	scope := NewScope(WithExtension(ext))
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	calls := 0
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errors.New("transient")
+		}
+		return calls, nil
+	}, WithFlowTag(FlowName(), "flaky"))
+
+	result, execCtx, err := Exec(scope, context.Background(), flow)
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if result != 3 {
+		t.Errorf("expected result 3, got %d", result)
+	}
+
+	if len(ext.ops) != 1 {
+		t.Fatalf("expected one exec operation, got %d", len(ext.ops))
+	}
+	op := ext.ops[0]
+	if op.ExecutionCtx != execCtx || op.Flow != AnyFlow(flow) || op.Executor != nil {
+		t.Errorf("unexpected operation contents: %+v", op)
+	}
+	if status, _ := execCtx.Get(statusTag); status != ExecutionStatusSuccess {
+		t.Errorf("expected status Success, got %v", status)
+	}
+}
+
+type ctxKey struct{}
+
+func TestFlowMiddleware(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	var order []string
+	trace := func(name string) FlowMiddleware {
+		return func(execCtx *ExecutionCtx, next func() (any, error)) (any, error) {
+			order = append(order, name+":before")
+			result, err := next()
+			order = append(order, name+":after")
+			return result, err
+		}
+	}
+
+	withValue := func(execCtx *ExecutionCtx, next func() (any, error)) (any, error) {
+		execCtx.SetContext(context.WithValue(execCtx.Context(), ctxKey{}, "tenant-a"))
+		return next()
+	}
+
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		order = append(order, "factory")
+		val, _ := execCtx.Context().Value(ctxKey{}).(string)
+		return val, nil
+	}, WithFlowMiddleware(trace("outer"), trace("inner")), WithFlowMiddleware(withValue))
+
+	result, _, err := Exec(scope, context.Background(), flow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "tenant-a" {
+		t.Errorf("expected factory to see modified context, got %q", result)
+	}
+
+	expected := []string{"outer:before", "inner:before", "factory", "inner:after", "outer:after"}
+	if fmt.Sprint(order) != fmt.Sprint(expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+
+	cached := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		t.Error("factory should not run when middleware substitutes a result")
+		return "", nil
+	}, WithFlowMiddleware(func(execCtx *ExecutionCtx, next func() (any, error)) (any, error) {
+		return "from-cache", nil
+	}))
+
+	parent := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		val, _, err := Exec1(execCtx, cached)
+		return val, err
+	})
+
+	result, _, err = Exec(scope, context.Background(), parent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "from-cache" {
+		t.Errorf("expected substituted result, got %q", result)
+	}
+
+	wrongType := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		return "", nil
+	}, WithFlowMiddleware(func(execCtx *ExecutionCtx, next func() (any, error)) (any, error) {
+		return 42, nil
+	}))
+
+	if _, _, err := Exec(scope, context.Background(), wrongType); err == nil {
+		t.Error("expected error when middleware returns the wrong type")
+	}
+}
+
+func TestFlowMiddlewareRetryRebuildsExecutionValues(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	type conn struct{ closed bool }
+
+	var built int
+	db := Provide(func(ctx *ResolveCtx) (*conn, error) {
+		built++
+		c := &conn{}
+		ctx.OnCleanup(func() error {
+			c.closed = true
+			return nil
+		})
+		return c, nil
+	})
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	retry := func(execCtx *ExecutionCtx, next func() (any, error)) (any, error) {
+		if _, err := next(); err == nil {
+			return nil, errors.New("expected first attempt to fail")
+		}
+		return next()
+	}
+
+	attempts := 0
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (bool, error) {
+		attempts++
+		c, err := ResolveInExecution(execCtx, db)
+		if err != nil {
+			return false, err
+		}
+		if attempts == 1 {
+			return false, errors.New("transient")
+		}
+		return c.closed, nil
+	}, WithFlowMiddleware(retry))
+
+	closed, _, err := Exec(scope, context.Background(), flow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if closed {
+		t.Error("retried attempt got a value whose cleanup already ran")
+	}
+	if built != 2 {
+		t.Errorf("expected the value to be rebuilt for the retry, built %d times", built)
+	}
+}
+
+func TestExecutionCtxConcurrentAccess(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	counterTag := NewTag[int]("test.counter")
+
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				SetValue(execCtx, counterTag, i)
+				_, _ = GetValue(execCtx, counterTag)
+				_, _ = execCtx.Lookup(FlowName())
+			}(i)
+		}
+		wg.Wait()
+		return GetValue(execCtx, counterTag)
+	})
+
+	if _, _, err := Exec(scope, context.Background(), flow); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTypedExecutionValues(t *testing.T) {
+	tenantTag := NewTag[string]("test.tenant")
+	regionTag := NewTag[string]("test.region")
+	limitTag := NewTag[int]("test.limit")
+
+	scope := NewScope(WithScopeTag(regionTag, "eu"))
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	child := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		if _, err := GetValue(execCtx, tenantTag); !errors.Is(err, ErrTagNotFound) {
+			return "", fmt.Errorf("expected ErrTagNotFound on own data, got %v", err)
+		}
+
+		tenant, err := LookupValue(execCtx, tenantTag)
+		if err != nil {
+			return "", err
+		}
+		region, err := LookupValue(execCtx, regionTag)
+		if err != nil {
+			return "", err
+		}
+
+		var typeErr *TagTypeError
+		if _, err := LookupValue(execCtx, limitTag); !errors.As(err, &typeErr) {
+			return "", fmt.Errorf("expected TagTypeError, got %v", err)
+		}
+		if typeErr.Key != "test.limit" {
+			return "", fmt.Errorf("unexpected key in type error: %q", typeErr.Key)
+		}
+
+		return tenant + "@" + region, nil
+	})
+
+	parent := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		SetValue(execCtx, tenantTag, "acme")
+		execCtx.Set(limitTag, "not-an-int")
+		result, _, err := Exec1(execCtx, child)
+		return result, err
+	})
+
+	result, _, err := Exec(scope, context.Background(), parent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "acme@eu" {
+		t.Errorf("expected 'acme@eu', got %q", result)
+	}
+}
+
+type skipExtension struct {
+	BaseExtension
+	output any
+}
+
+func (e *skipExtension) OnFlowStart(execCtx *ExecutionCtx, flow AnyFlow) error {
+	execCtx.Set(skipExecTag, true)
+	execCtx.Set(cachedTag, e.output)
+	return nil
+}
+
+func TestExecHonorsSkipExecution(t *testing.T) {
+	scope := NewScope(WithExtension(&skipExtension{
+		BaseExtension: NewBaseExtension("skip"),
+		output:        "cached",
+	}))
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+
+	ran := false
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (string, error) {
+		ran = true
+		return "fresh", nil
+	})
+
+	result, execCtx, err := Exec(scope, context.Background(), flow)
+	if err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if result != "cached" || ran {
+		t.Errorf("expected cached output without running the factory, got %q (ran=%v)", result, ran)
+	}
+
+	node := scope.GetExecutionTree().GetNode(execCtx.ID())
+	if node == nil {
+		t.Fatal("expected skipped execution to be recorded")
+	}
+	if status, _ := node.GetTag(statusTag); status != ExecutionStatusSuccess {
+		t.Errorf("expected success status, got %v", status)
+	}
+
+	mismatched := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 1, nil
+	})
+	if _, _, err := Exec(scope, context.Background(), mismatched); err == nil {
+		t.Error("expected cached output of the wrong type to fail")
+	}
+}
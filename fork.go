@@ -0,0 +1,116 @@
+package pumped
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+type forkConfig struct {
+	copy    func(exec AnyExecutor, val any) any
+	copiers map[AnyExecutor]func(any) any
+}
+
+// ForkOption configures Scope.Fork
+type ForkOption func(*forkConfig)
+
+// ForkCopy sets a hook that copies cached values into the fork. Without it
+// forks share the parent's values, which suits immutable fixtures.
+func ForkCopy(fn func(exec AnyExecutor, val any) any) ForkOption {
+	return func(cfg *forkConfig) {
+		cfg.copy = fn
+	}
+}
+
+// ForkCopyFor sets how one executor's cached value is copied into the fork,
+// taking precedence over ForkCopy
+func ForkCopyFor[T any](exec *Executor[T], fn func(T) T) ForkOption {
+	return func(cfg *forkConfig) {
+		if cfg.copiers == nil {
+			cfg.copiers = make(map[AnyExecutor]func(any) any)
+		}
+		cfg.copiers[exec] = func(val any) any {
+			return fn(val.(T))
+		}
+	}
+}
+
+// Fork returns an independent scope that starts from the parent's cached
+// values, presets, decorators and tags. The fork has its own cleanup
+// registry, reactive graph and execution tree, so Update, SetPreset and
+// Dispose on it never affect the parent or sibling forks. Cleanups of
+// copied values stay with the parent. The execution tree starts empty with
+// the parent's limits and retention.
+//
+// Extensions implementing ForkableExtension get a fresh instance in the
+// fork; others are shared and are not disposed by the fork. When a fresh
+// instance fails to initialize, the instances already initialized are
+// disposed and the error is returned. Job queues and schedulers are not
+// carried over.
+func (s *Scope) Fork(opts ...ForkOption) (*Scope, error) {
+	cfg := &forkConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	s.mu.RLock()
+	presets := maps.Clone(s.presets)
+	decorators := make(map[AnyExecutor][]decorator, len(s.decorators))
+	for exec, decs := range s.decorators {
+		decorators[exec] = append([]decorator(nil), decs...)
+	}
+	exts := make([]Extension, len(s.extensions))
+	copy(exts, s.extensions)
+	s.mu.RUnlock()
+
+	fork := &Scope{
+		extensions:      []Extension{},
+		presets:         presets,
+		decorators:      decorators,
+		cleanupRegistry: make(map[AnyExecutor][]cleanupEntry),
+		execTree:        s.execTree.emptyCopy(),
+		graph:           s.graph.clone(),
+		gracePeriod:     s.gracePeriod,
+		stackTraces:     s.stackTraces,
+	}
+
+	s.tags.Range(func(key, val any) bool {
+		fork.tags.Store(key, val)
+		return true
+	})
+
+	s.cache.Range(func(key, val any) bool {
+		exec := key.(AnyExecutor)
+		if copier, ok := cfg.copiers[exec]; ok {
+			val = copier(val)
+		} else if cfg.copy != nil {
+			val = cfg.copy(exec, val)
+		}
+		fork.cache.Store(exec, val)
+		return true
+	})
+
+	// Keep the parent's order, replacing forkable extensions in place
+	var fresh []Extension
+	fork.shared = make(map[Extension]bool)
+	for i, ext := range exts {
+		if f, ok := ext.(ForkableExtension); ok {
+			exts[i] = f.Fork()
+			fresh = append(fresh, exts[i])
+			continue
+		}
+		fork.shared[ext] = true
+	}
+	fork.extensions = exts
+	for i, ext := range fresh {
+		if err := ext.Init(fork); err != nil {
+			err = fmt.Errorf("fork: initializing extension %s: %w", ext.Name(), err)
+			for _, initialized := range fresh[:i] {
+				err = errors.Join(err, initialized.Dispose(fork))
+			}
+			return nil, err
+		}
+	}
+
+	return fork, nil
+}
@@ -0,0 +1,207 @@
+package pumped
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+type forkCounter struct {
+	BaseExtension
+	resolves *atomic.Int32
+	disposed *atomic.Int32
+}
+
+func (c *forkCounter) Wrap(ctx context.Context, next func() (any, error), op *Operation) (any, error) {
+	if op.Kind == OpResolve {
+		c.resolves.Add(1)
+	}
+	return next()
+}
+
+func (c *forkCounter) Dispose(scope *Scope) error {
+	c.disposed.Add(1)
+	return nil
+}
+
+func (c *forkCounter) Fork() Extension {
+	return &forkCounter{
+		BaseExtension: c.BaseExtension,
+		resolves:      &atomic.Int32{},
+		disposed:      c.disposed,
+	}
+}
+
+func TestForkIsolatesUpdates(t *testing.T) {
+	parent := NewScope()
+	defer parent.Dispose()
+
+	var fixtureBuilds atomic.Int32
+	fixture := Provide(func(ctx *ResolveCtx) (string, error) {
+		fixtureBuilds.Add(1)
+		return "fixture", nil
+	})
+	counter := Provide(func(ctx *ResolveCtx) (int, error) {
+		return 0, nil
+	})
+	doubled := Derive1(counter.Reactive(), func(ctx *ResolveCtx, c *Controller[int]) (int, error) {
+		v, err := c.Get()
+		return v * 2, err
+	})
+
+	_, _ = Resolve(parent, fixture)
+	_, _ = Resolve(parent, doubled)
+
+	a, err := parent.Fork()
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	defer a.Dispose()
+	b, err := parent.Fork()
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	defer b.Dispose()
+
+	if err := Update(context.Background(), a, counter, 5); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	if got, _ := Resolve(a, doubled); got != 10 {
+		t.Errorf("expected fork to see its update, got %d", got)
+	}
+	if got, _ := Resolve(b, doubled); got != 0 {
+		t.Errorf("expected sibling fork untouched, got %d", got)
+	}
+	if got, _ := Resolve(parent, doubled); got != 0 {
+		t.Errorf("expected parent untouched, got %d", got)
+	}
+
+	_, _ = Resolve(a, fixture)
+	_, _ = Resolve(b, fixture)
+	if fixtureBuilds.Load() != 1 {
+		t.Errorf("expected forks to start from the parent's cache, got %d builds", fixtureBuilds.Load())
+	}
+}
+
+func TestForkCopiesValues(t *testing.T) {
+	parent := NewScope()
+	defer parent.Dispose()
+
+	items := Provide(func(ctx *ResolveCtx) ([]string, error) {
+		return []string{"a"}, nil
+	})
+	_, _ = Resolve(parent, items)
+
+	fork, err := parent.Fork(ForkCopyFor(items, slices.Clone[[]string]))
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	defer fork.Dispose()
+
+	forked, _ := Resolve(fork, items)
+	forked[0] = "changed"
+
+	if original, _ := Resolve(parent, items); original[0] != "a" {
+		t.Errorf("expected deep copy to protect the parent, got %v", original)
+	}
+}
+
+func TestForkExtensions(t *testing.T) {
+	disposed := &atomic.Int32{}
+	ext := &forkCounter{
+		BaseExtension: NewBaseExtension("counter"),
+		resolves:      &atomic.Int32{},
+		disposed:      disposed,
+	}
+	parent := NewScope(WithExtension(ext))
+
+	fork, err := parent.Fork()
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	exec := Provide(func(ctx *ResolveCtx) (int, error) { return 1, nil })
+	_, _ = Resolve(fork, exec)
+
+	if ext.resolves.Load() != 0 {
+		t.Error("expected the fork to use its own extension instance")
+	}
+
+	_ = fork.Dispose()
+	_ = parent.Dispose()
+	if disposed.Load() != 2 {
+		t.Errorf("expected each instance disposed once, got %d", disposed.Load())
+	}
+}
+
+type failingForkExtension struct {
+	BaseExtension
+	fail bool
+}
+
+func (f *failingForkExtension) Fork() Extension {
+	return &failingForkExtension{BaseExtension: f.BaseExtension, fail: true}
+}
+
+// Order runs the failing extension after forkCounter
+func (f *failingForkExtension) Order() int { return 200 }
+
+func (f *failingForkExtension) Init(scope *Scope) error {
+	if f.fail {
+		return errors.New("cannot fork")
+	}
+	return nil
+}
+
+func TestForkReportsExtensionInitFailure(t *testing.T) {
+	disposed := &atomic.Int32{}
+	counter := &forkCounter{
+		BaseExtension: NewBaseExtension("counter"),
+		resolves:      &atomic.Int32{},
+		disposed:      disposed,
+	}
+	parent := NewScope(
+		WithExtension(counter),
+		WithExtension(&failingForkExtension{BaseExtension: NewBaseExtension("failing")}),
+	)
+	defer parent.Dispose()
+
+	fork, err := parent.Fork()
+	if err == nil || fork != nil {
+		t.Fatalf("expected fork to fail, got %v, %v", fork, err)
+	}
+	if disposed.Load() != 1 {
+		t.Errorf("expected the initialized fork extension to be disposed, got %d", disposed.Load())
+	}
+}
+
+func TestForkKeepsExecutionTreeConfig(t *testing.T) {
+	var evicted atomic.Int32
+	parent := NewScope(
+		WithExecutionTreeMaxNodes(1),
+		WithExecutionTreeEvictionHandler(func(*ExecutionNode) { evicted.Add(1) }),
+	)
+	defer parent.Dispose()
+
+	fork, err := parent.Fork()
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	defer fork.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) { return struct{}{}, nil })
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) { return 1, nil })
+	for i := 0; i < 3; i++ {
+		if _, _, err := Exec(fork, context.Background(), flow); err != nil {
+			t.Fatalf("exec failed: %v", err)
+		}
+	}
+	if n := len(fork.GetExecutionTree().GetRoots()); n != 1 {
+		t.Errorf("expected the fork to keep the parent's node limit, got %d roots", n)
+	}
+	if evicted.Load() != 2 {
+		t.Errorf("expected the parent's eviction hook, got %d evictions", evicted.Load())
+	}
+}
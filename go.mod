@@ -2,4 +2,4 @@ module github.com/pumped-fn/pumped-go
 
 go 1.23
 
-require github.com/m1gwings/treedrawer v0.3.3-beta // indirect
+require github.com/m1gwings/treedrawer v0.3.3-beta
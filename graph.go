@@ -56,6 +56,28 @@ func (g *ReactiveGraph) RemoveDependency(dependent AnyExecutor, dependency AnyEx
 	}
 }
 
+// RemoveExecutor removes every relationship in which executor is the
+// dependent or the dependency
+func (g *ReactiveGraph) RemoveExecutor(executor AnyExecutor) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, dependent := range g.downstream[executor] {
+		g.upstream[dependent] = removeElement(g.upstream[dependent], executor)
+		if len(g.upstream[dependent]) == 0 {
+			delete(g.upstream, dependent)
+		}
+	}
+	for _, dependency := range g.upstream[executor] {
+		g.downstream[dependency] = removeElement(g.downstream[dependency], executor)
+		if len(g.downstream[dependency]) == 0 {
+			delete(g.downstream, dependency)
+		}
+	}
+	delete(g.downstream, executor)
+	delete(g.upstream, executor)
+}
+
 // FindDependents performs iterative traversal to find all reactive dependents
 // This replaces the recursive implementation to prevent stack overflow
 func (g *ReactiveGraph) FindDependents(start AnyExecutor) []AnyExecutor {
@@ -132,6 +154,21 @@ func (g *ReactiveGraph) ExportAllDependencies() map[AnyExecutor][]AnyExecutor {
 	return result
 }
 
+// clone returns an independent copy of the graph
+func (g *ReactiveGraph) clone() *ReactiveGraph {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	c := NewReactiveGraph()
+	for k, v := range g.downstream {
+		c.downstream[k] = append([]AnyExecutor(nil), v...)
+	}
+	for k, v := range g.upstream {
+		c.upstream[k] = append([]AnyExecutor(nil), v...)
+	}
+	return c
+}
+
 // Internal helper methods
 
 func (g *ReactiveGraph) resetVisited() {
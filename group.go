@@ -0,0 +1,162 @@
+package pumped
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var groupPriorityTag = NewTag[int]("group.priority")
+
+// GroupPriority is the tag that orders group members; lower values come
+// first and members without it have priority 0
+func GroupPriority() Tag[int] { return groupPriorityTag }
+
+// WithGroupPriority returns an option that sets an executor's group priority
+func WithGroupPriority(priority int) ExecutorOption {
+	return func(exec AnyExecutor) {
+		groupPriorityTag.Set(exec, priority)
+	}
+}
+
+// Group collects executors contributed by many packages, such as route
+// registrars or health checks, so one consumer can receive all of them.
+//
+//	var Routes = pumped.NewGroup[Route]("routes")
+//
+//	var users = pumped.Derive1(db, newUserRoutes, pumped.JoinGroup(Routes))
+//
+//	var server = pumped.DeriveGroup(Routes, func(ctx *pumped.ResolveCtx, routes []Route) (*Server, error) {
+//	    return NewServer(routes), nil
+//	})
+//
+// Members join when they are constructed, so groups should be resolved
+// after package initialization. Membership is frozen once the group is
+// first resolved; joining later panics.
+type Group[T any] struct {
+	name string
+	all  *Executor[[]T]
+
+	mu      sync.Mutex
+	members []*Executor[T]
+	frozen  bool
+}
+
+type groupMembersKey struct {
+	group any
+}
+
+// NewGroup creates an empty group
+func NewGroup[T any](name string) *Group[T] {
+	g := &Group[T]{name: name}
+	g.all = Provide(func(ctx *ResolveCtx) ([]T, error) {
+		return g.resolveMembers(ctx)
+	})
+	executorNameTag.Set(g.all, "group:"+name)
+	return g
+}
+
+// Name returns the group name
+func (g *Group[T]) Name() string {
+	return g.name
+}
+
+// All returns the executor that resolves every member, in priority order.
+// Members are reactive dependencies, so updating one re-resolves the group.
+// Preset it to replace the whole group in tests.
+func (g *Group[T]) All() *Executor[[]T] {
+	return g.all
+}
+
+// Members returns the executors that joined the group at construction
+func (g *Group[T]) Members() []*Executor[T] {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.members)
+}
+
+func (g *Group[T]) join(exec *Executor[T]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// The scope reads the group's dependencies without this lock
+	if g.frozen {
+		panic(fmt.Sprintf("pumped: executor cannot join group %q after it was resolved", g.name))
+	}
+	g.members = append(g.members, exec)
+	g.all.deps = append(g.all.deps, exec.Reactive())
+}
+
+// JoinGroup returns an option that adds the executor to g. It panics if the
+// executor's value type is not the group's element type or g was already
+// resolved.
+func JoinGroup[T any](g *Group[T]) ExecutorOption {
+	return func(exec AnyExecutor) {
+		typed, ok := exec.(*Executor[T])
+		if !ok {
+			panic(fmt.Sprintf("pumped: executor %T cannot join group %q of %T", exec, g.name, *new(T)))
+		}
+		g.join(typed)
+	}
+}
+
+// WithGroupMember returns an option that adds members to g in one scope
+// only, e.g. a test double or a plugin enabled by configuration
+func WithGroupMember[T any](g *Group[T], members ...*Executor[T]) ScopeOption {
+	return func(s *Scope) {
+		key := groupMembersKey{group: g}
+		var existing []*Executor[T]
+		if val, ok := s.tags.Load(key); ok {
+			existing = val.([]*Executor[T])
+		}
+		s.tags.Store(key, append(slices.Clone(existing), members...))
+	}
+}
+
+func (g *Group[T]) resolveMembers(ctx *ResolveCtx) ([]T, error) {
+	g.mu.Lock()
+	g.frozen = true
+	members := slices.Clone(g.members)
+	g.mu.Unlock()
+
+	if val, ok := ctx.scope.GetTag(groupMembersKey{group: g}); ok {
+		extra := val.([]*Executor[T])
+		ctx.scope.mu.Lock()
+		for _, exec := range extra {
+			ctx.scope.graph.AddDependency(g.all, exec)
+		}
+		ctx.scope.mu.Unlock()
+		members = append(members, extra...)
+	}
+
+	slices.SortStableFunc(members, func(a, b *Executor[T]) int {
+		return cmp.Compare(groupPriorityTag.GetOrDefault(a, 0), groupPriorityTag.GetOrDefault(b, 0))
+	})
+
+	values := make([]T, 0, len(members))
+	for _, exec := range members {
+		val, err := resolveFor(ctx.scope, exec, ctx)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, val)
+	}
+	return values, nil
+}
+
+// DeriveGroup creates an executor that receives every member of g
+func DeriveGroup[T any, R any](
+	g *Group[T],
+	factory func(*ResolveCtx, []T) (R, error),
+	opts ...ExecutorOption,
+) *Executor[R] {
+	return Derive1(g.all.Reactive(), func(ctx *ResolveCtx, all *Controller[[]T]) (R, error) {
+		values, err := all.Get()
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return factory(ctx, values)
+	}, opts...)
+}
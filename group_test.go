@@ -0,0 +1,115 @@
+package pumped
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestGroupCollectsMembersByPriority(t *testing.T) {
+	checks := NewGroup[string]("health")
+
+	db := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "db", nil
+	}, JoinGroup(checks), WithGroupPriority(2))
+
+	Provide(func(ctx *ResolveCtx) (string, error) {
+		return "cache", nil
+	}, JoinGroup(checks), WithGroupPriority(1))
+
+	Provide(func(ctx *ResolveCtx) (string, error) {
+		return "queue", nil
+	}, JoinGroup(checks), WithGroupPriority(2))
+
+	report := DeriveGroup(checks, func(ctx *ResolveCtx, names []string) ([]string, error) {
+		return names, nil
+	})
+
+	scope := NewScope()
+	defer scope.Dispose()
+
+	names, err := Resolve(scope, report)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if !slices.Equal(names, []string{"cache", "db", "queue"}) {
+		t.Errorf("expected members ordered by priority then join order, got %v", names)
+	}
+
+	if err := Update(context.Background(), scope, db, "postgres"); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	names, _ = Resolve(scope, report)
+	if !slices.Equal(names, []string{"cache", "postgres", "queue"}) {
+		t.Errorf("expected group to re-resolve after a member update, got %v", names)
+	}
+}
+
+func TestGroupScopeMembersAndPresets(t *testing.T) {
+	routes := NewGroup[string]("routes")
+
+	users := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "/users", nil
+	}, JoinGroup(routes))
+
+	debug := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "/debug", nil
+	})
+
+	plain := NewScope()
+	defer plain.Dispose()
+	extended := NewScope(WithGroupMember(routes, debug))
+	defer extended.Dispose()
+	preset := NewScope(WithPreset(users, "/fake-users"))
+	defer preset.Dispose()
+
+	got, _ := Resolve(plain, routes.All())
+	if !slices.Equal(got, []string{"/users"}) {
+		t.Errorf("unexpected members %v", got)
+	}
+
+	got, _ = Resolve(extended, routes.All())
+	if !slices.Equal(got, []string{"/users", "/debug"}) {
+		t.Errorf("expected scope member to extend the group, got %v", got)
+	}
+
+	got, _ = Resolve(preset, routes.All())
+	if !slices.Equal(got, []string{"/fake-users"}) {
+		t.Errorf("expected member preset to apply, got %v", got)
+	}
+}
+
+func TestJoinGroupTypeMismatchPanics(t *testing.T) {
+	checks := NewGroup[string]("checks")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for mismatched member type")
+		}
+	}()
+	Provide(func(ctx *ResolveCtx) (int, error) {
+		return 0, nil
+	}, JoinGroup(checks))
+}
+
+func TestJoinGroupAfterResolvePanics(t *testing.T) {
+	checks := NewGroup[string]("checks")
+	Provide(func(ctx *ResolveCtx) (string, error) {
+		return "db", nil
+	}, JoinGroup(checks))
+
+	scope := NewScope()
+	defer scope.Dispose()
+	if _, err := Resolve(scope, checks.All()); err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for a member joining a resolved group")
+		}
+	}()
+	Provide(func(ctx *ResolveCtx) (string, error) {
+		return "late", nil
+	}, JoinGroup(checks))
+}
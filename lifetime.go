@@ -0,0 +1,178 @@
+package pumped
+
+import "fmt"
+
+// Lifetime controls how long a resolved executor value is reused
+type Lifetime int
+
+const (
+	// Singleton values are built once and cached by the scope (default)
+	Singleton Lifetime = iota
+	// Scoped values are built once per scope that resolves them, and once
+	// per execution when requested from a flow
+	Scoped
+	// Transient values are built on every request and never cached.
+	// Cleanups belong to the requester: the executor or flow execution
+	// that asked for the value. A transient that registers cleanups must be
+	// requested from one; top-level Resolve calls fail with
+	// ErrTransientCleanup.
+	Transient
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Scoped:
+		return "scoped"
+	case Transient:
+		return "transient"
+	default:
+		return fmt.Sprintf("lifetime(%d)", int(l))
+	}
+}
+
+var lifetimeTag = NewTag[Lifetime]("executor.lifetime")
+
+// WithLifetime returns an option that sets an executor's lifetime
+func WithLifetime(l Lifetime) ExecutorOption {
+	return func(exec AnyExecutor) {
+		lifetimeTag.Set(exec, l)
+	}
+}
+
+// LifetimeOf returns the lifetime declared on an executor
+func LifetimeOf(exec AnyExecutor) Lifetime {
+	if val, ok := exec.GetTag(lifetimeTag); ok {
+		if l, ok := val.(Lifetime); ok {
+			return l
+		}
+	}
+	return Singleton
+}
+
+// validateLifetime rejects singletons that capture shorter-lived
+// dependencies, which would otherwise be frozen in the scope cache
+func validateLifetime(exec AnyExecutor) error {
+	if LifetimeOf(exec) != Singleton {
+		return nil
+	}
+	for _, dep := range exec.GetDeps() {
+		depExec := dep.GetExecutor()
+		if l := LifetimeOf(depExec); l != Singleton {
+			return &LifetimeError{
+				Executor:           exec,
+				Lifetime:           Singleton,
+				Dependency:         depExec,
+				DependencyLifetime: l,
+			}
+		}
+	}
+	return nil
+}
+
+// cleanupOwner receives the cleanups of transient values it requested
+type cleanupOwner interface {
+	OnCleanup(fn func() error)
+}
+
+// resolveFor resolves exec on behalf of a requester, which is nil, the
+// ResolveCtx of a dependent executor, or the ExecutionCtx of a flow
+func resolveFor[T any](s *Scope, exec *Executor[T], requester cleanupOwner) (T, error) {
+	touchMember(exec)
+
+	if rc, ok := requester.(*ResolveCtx); ok && rc.execCtx != nil {
+		requester = rc.execCtx
+	}
+
+	switch LifetimeOf(exec) {
+	case Transient:
+		return resolveTransient(s, exec, requester)
+	case Scoped:
+		if e, ok := requester.(*ExecutionCtx); ok {
+			return ResolveInExecution(e, exec)
+		}
+		return resolveCached(s, exec, false)
+	default:
+		return resolveCached(s, exec, true)
+	}
+}
+
+// resolveTransient builds a fresh value and hands its cleanups to the
+// requester
+func resolveTransient[T any](s *Scope, exec *Executor[T], requester cleanupOwner) (T, error) {
+	var zero T
+
+	if p, hasPreset := s.lookupPreset(exec); hasPreset {
+		val := p.value
+		if !p.isValue {
+			var err error
+			if val, err = resolveDependency(s, p.executor); err != nil {
+				return zero, s.resolveError(exec, err, "preset")
+			}
+		}
+		val, err := s.decorate(exec, val, nil)
+		if err != nil {
+			return zero, err
+		}
+		return presetValue[T](exec, val)
+	}
+
+	for _, dep := range exec.deps {
+		if dep.GetMode() == ModeLazy || LifetimeOf(dep.GetExecutor()) != Singleton {
+			continue
+		}
+		if _, err := resolveDeclared(s, dep); err != nil && dep.GetMode() != ModeOptional {
+			return zero, s.resolveError(exec, err, "dependency")
+		}
+	}
+
+	ctx := &ResolveCtx{
+		scope:      s,
+		executorID: exec,
+	}
+	if e, ok := requester.(*ExecutionCtx); ok {
+		ctx.execCtx = e
+	}
+
+	s.mu.RLock()
+	exts := s.extensions
+	s.mu.RUnlock()
+
+	result, err := wrapResolve(s, exts, exec, func() (any, error) {
+		return exec.runFactory(s, ctx)
+	})
+	if err != nil {
+		return zero, s.resolveError(exec, err, "factory")
+	}
+	result, err = s.decorate(exec, result, ctx)
+	if err != nil {
+		return zero, err
+	}
+
+	cleanups := ctx.takeCleanups()
+	if requester == nil && len(cleanups) > 0 {
+		// Nothing releases a top-level transient value, so its cleanups
+		// would pile up in the scope until Dispose
+		s.runCleanups(cleanups, exec, "transient")
+		return zero, s.resolveError(exec, ErrTransientCleanup, "lifetime")
+	}
+	for _, entry := range cleanups {
+		requester.OnCleanup(entry.fn)
+	}
+	s.publishExecutor(EventExecutorResolved, exec)
+
+	typed, err := SafeTypeAssertion[T](result)
+	if err != nil {
+		return zero, s.resolveError(exec, err, "resolution_type_assertion")
+	}
+	return typed, nil
+}
+
+func presetValue[T any](exec AnyExecutor, val any) (T, error) {
+	typed, err := SafeTypeAssertion[T](val)
+	if err != nil {
+		return typed, CreateResolveError(exec, err, "preset_value_type_assertion")
+	}
+	return typed, nil
+}
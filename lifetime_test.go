@@ -0,0 +1,172 @@
+package pumped
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTransientResolvesFreshValues(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	var builds atomic.Int32
+	buffer := Provide(func(ctx *ResolveCtx) (int32, error) {
+		return builds.Add(1), nil
+	}, WithLifetime(Transient))
+
+	first, err := Resolve(scope, buffer)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	second, _ := Accessor(scope, buffer).Get()
+	if first == second {
+		t.Errorf("expected fresh values, got %d twice", first)
+	}
+	if Accessor(scope, buffer).IsCached() {
+		t.Error("transient value was cached")
+	}
+}
+
+func TestTransientCleanupsNeedRequester(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	var cleanups atomic.Int32
+	conn := Provide(func(ctx *ResolveCtx) (string, error) {
+		ctx.OnCleanup(func() error {
+			cleanups.Add(1)
+			return nil
+		})
+		return "conn", nil
+	}, WithLifetime(Transient))
+
+	for i := 0; i < 100; i++ {
+		if _, err := Resolve(scope, conn); !errors.Is(err, ErrTransientCleanup) {
+			t.Fatalf("expected ErrTransientCleanup, got %v", err)
+		}
+	}
+	if cleanups.Load() != 100 {
+		t.Errorf("expected cleanups to run on the failed resolve, got %d", cleanups.Load())
+	}
+
+	scope.cleanupMu.Lock()
+	registered := len(scope.cleanupRegistry)
+	scope.cleanupMu.Unlock()
+	if registered != 0 {
+		t.Errorf("expected no cleanups left in the scope, got %d executors", registered)
+	}
+}
+
+func TestTransientCleanupsOwnedByRequester(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	var released atomic.Int32
+	conn := Provide(func(ctx *ResolveCtx) (string, error) {
+		ctx.OnCleanup(func() error {
+			released.Add(1)
+			return nil
+		})
+		return "conn", nil
+	}, WithLifetime(Transient))
+
+	repo := Derive1(conn, func(ctx *ResolveCtx, c *Controller[string]) (string, error) {
+		v, err := c.Get()
+		return "repo:" + v, err
+	}, WithLifetime(Scoped))
+
+	if _, err := Resolve(scope, repo); err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if released.Load() != 0 {
+		t.Fatal("transient cleanup ran before its requester was released")
+	}
+
+	scope.cleanupExecutor(repo)
+	if released.Load() != 1 {
+		t.Errorf("expected transient cleanup with its requester, got %d", released.Load())
+	}
+}
+
+func TestScopedValuesPerExecution(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	var builds, released atomic.Int32
+	validator := Provide(func(ctx *ResolveCtx) (int32, error) {
+		ctx.OnCleanup(func() error {
+			released.Add(1)
+			return nil
+		})
+		return builds.Add(1), nil
+	}, WithLifetime(Scoped))
+
+	flow := Flow1(validator, func(execCtx *ExecutionCtx, v *Controller[int32]) (int32, error) {
+		a, err := v.Get()
+		if err != nil {
+			return 0, err
+		}
+		b, _ := v.Get()
+		if a != b {
+			t.Errorf("expected one instance per execution, got %d and %d", a, b)
+		}
+		return a, nil
+	})
+
+	first, _, err := Exec(scope, context.Background(), flow)
+	if err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+	second, _, err := Exec(scope, context.Background(), flow)
+	if err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+
+	if first == second {
+		t.Errorf("expected a fresh instance per execution, got %d twice", first)
+	}
+	if released.Load() != 2 {
+		t.Errorf("expected cleanups when each execution ended, got %d", released.Load())
+	}
+	if Accessor(scope, validator).IsCached() {
+		t.Error("execution scoped value leaked into the scope cache")
+	}
+}
+
+func TestSingletonRejectsShorterLivedDependency(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	request := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "request", nil
+	}, WithLifetime(Scoped))
+
+	service := Derive1(request, func(ctx *ResolveCtx, r *Controller[string]) (string, error) {
+		return r.Get()
+	})
+
+	_, err := Resolve(scope, service)
+
+	var lifetimeErr *LifetimeError
+	if !errors.As(err, &lifetimeErr) {
+		t.Fatalf("expected LifetimeError, got %v", err)
+	}
+	if lifetimeErr.Dependency != AnyExecutor(request) || lifetimeErr.DependencyLifetime != Scoped {
+		t.Errorf("unexpected lifetime error: %v", lifetimeErr)
+	}
+}
+
+func TestUpdateRejectsTransient(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	counter := Provide(func(ctx *ResolveCtx) (int, error) {
+		return 0, nil
+	}, WithLifetime(Transient))
+
+	if err := Update(context.Background(), scope, counter, 1); err == nil {
+		t.Error("expected update of a transient executor to fail")
+	}
+}
@@ -0,0 +1,112 @@
+package pumped
+
+// newOverlayScope creates a scope that layers execution-local presets over
+// parent. Executors unaffected by the presets resolve through the parent and
+// share its cache; executors that are overridden, or depend on an override,
+// are resolved and cached in the overlay only.
+func newOverlayScope(parent *Scope, presets map[AnyExecutor]preset) *Scope {
+	parent.mu.RLock()
+	exts := make([]Extension, len(parent.extensions))
+	copy(exts, parent.extensions)
+	parent.mu.RUnlock()
+
+	return &Scope{
+		extensions:      exts,
+		presets:         presets,
+		cleanupRegistry: make(map[AnyExecutor][]cleanupEntry),
+		execTree:        parent.execTree,
+		graph:           NewReactiveGraph(),
+		gracePeriod:     parent.gracePeriod,
+		stackTraces:     parent.stackTraces,
+		parent:          parent,
+		overrideMemo:    make(map[AnyExecutor]bool),
+	}
+}
+
+// root returns the scope that owns shared state such as counters
+func (s *Scope) root() *Scope {
+	for s.parent != nil {
+		s = s.parent
+	}
+	return s
+}
+
+// lookupPreset finds the preset for exec in this scope or its parents
+func (s *Scope) lookupPreset(exec AnyExecutor) (preset, bool) {
+	for cur := s; cur != nil; cur = cur.parent {
+		cur.mu.RLock()
+		p, ok := cur.presets[exec]
+		cur.mu.RUnlock()
+		if ok {
+			return p, true
+		}
+	}
+	return preset{}, false
+}
+
+// overrides reports whether exec must be resolved in this overlay because it
+// is overridden here or transitively depends on an override
+func (s *Scope) overrides(exec AnyExecutor) bool {
+	s.overrideMu.Lock()
+	defer s.overrideMu.Unlock()
+
+	return s.computeOverrides(exec, make(map[AnyExecutor]bool))
+}
+
+func (s *Scope) computeOverrides(exec AnyExecutor, visiting map[AnyExecutor]bool) bool {
+	if result, ok := s.overrideMemo[exec]; ok {
+		return result
+	}
+	if visiting[exec] {
+		return false
+	}
+	visiting[exec] = true
+
+	result := false
+	if _, ok := s.presets[exec]; ok {
+		result = true
+	} else if p, ok := s.parent.lookupPreset(exec); ok {
+		result = !p.isValue && s.computeOverrides(p.executor, visiting)
+	} else {
+		for _, dep := range exec.GetDeps() {
+			if s.computeOverrides(dep.GetExecutor(), visiting) {
+				result = true
+				break
+			}
+		}
+	}
+
+	s.overrideMemo[exec] = result
+	return result
+}
+
+// owner returns the scope whose cache holds exec
+func (s *Scope) owner(exec AnyExecutor) *Scope {
+	if s.parent != nil && LifetimeOf(exec) == Singleton && !s.overrides(exec) {
+		return s.parent.owner(exec)
+	}
+	return s
+}
+
+// releaseOverlay runs cleanups for values resolved in an overlay and drops
+// its cache. It is a no-op on regular scopes.
+func (s *Scope) releaseOverlay() {
+	if s.parent == nil {
+		return
+	}
+
+	s.cleanupMu.Lock()
+	registry := s.cleanupRegistry
+	s.cleanupRegistry = make(map[AnyExecutor][]cleanupEntry)
+	s.cleanupMu.Unlock()
+
+	for exec, entries := range registry {
+		s.runCleanups(entries, exec, "execution")
+	}
+
+	s.cache.Range(func(key, _ any) bool {
+		s.cache.Delete(key)
+		return true
+	})
+	s.failures.Clear()
+}
@@ -0,0 +1,108 @@
+package pumped
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExecPresetIsolation(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	var poolBuilds atomic.Int32
+	pool := Provide(func(ctx *ResolveCtx) (string, error) {
+		poolBuilds.Add(1)
+		return "pool", nil
+	})
+
+	tenant := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "default", nil
+	})
+
+	var released atomic.Int32
+	repo := Derive2(pool, tenant, func(ctx *ResolveCtx, p *Controller[string], tn *Controller[string]) (string, error) {
+		pv, _ := p.Get()
+		tv, _ := tn.Get()
+		ctx.OnCleanup(func() error {
+			released.Add(1)
+			return nil
+		})
+		return pv + "/" + tv, nil
+	})
+
+	child := Flow1(repo, func(execCtx *ExecutionCtx, r *Controller[string]) (string, error) {
+		return r.Get()
+	})
+
+	parent := Flow1(repo, func(execCtx *ExecutionCtx, r *Controller[string]) ([]string, error) {
+		own, err := r.Get()
+		if err != nil {
+			return nil, err
+		}
+		sub, _, err := Exec1(execCtx, child)
+		if err != nil {
+			return nil, err
+		}
+		return []string{own, sub}, nil
+	})
+
+	result, _, err := Exec(scope, context.Background(), parent, WithExecPreset(tenant, "acme"))
+	if err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+	if result[0] != "pool/acme" || result[1] != "pool/acme" {
+		t.Errorf("expected overrides in parent and child flow, got %v", result)
+	}
+
+	if Accessor(scope, repo).IsCached() || Accessor(scope, tenant).IsCached() {
+		t.Error("overridden values leaked into the shared scope cache")
+	}
+	if !Accessor(scope, pool).IsCached() {
+		t.Error("unaffected dependency should resolve through the shared cache")
+	}
+	if released.Load() != 1 {
+		t.Errorf("expected execution-local cleanup to run once, got %d", released.Load())
+	}
+
+	result, _, err = Exec(scope, context.Background(), parent)
+	if err != nil {
+		t.Fatalf("exec without presets failed: %v", err)
+	}
+	if result[0] != "pool/default" {
+		t.Errorf("expected shared value without presets, got %v", result)
+	}
+
+	if poolBuilds.Load() != 1 {
+		t.Errorf("expected shared pool to be built once, got %d", poolBuilds.Load())
+	}
+}
+
+func TestExecPresetExecutorReplacement(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	clock := Provide(func(ctx *ResolveCtx) (int, error) {
+		return 1, nil
+	})
+	fakeClock := Provide(func(ctx *ResolveCtx) (int, error) {
+		return 99, nil
+	})
+
+	flow := Flow1(clock, func(execCtx *ExecutionCtx, c *Controller[int]) (int, error) {
+		return c.Get()
+	})
+
+	result, _, err := Exec(scope, context.Background(), flow, WithExecPreset(clock, fakeClock))
+	if err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+	if result != 99 {
+		t.Errorf("expected replacement executor value 99, got %d", result)
+	}
+
+	_, _, err = Exec(scope, context.Background(), flow, WithExecPreset(clock, "wrong type"))
+	if err == nil {
+		t.Error("expected error for mistyped preset")
+	}
+}
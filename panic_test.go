@@ -0,0 +1,97 @@
+package pumped
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type panicRecorder struct {
+	BaseExtension
+	calls atomic.Int32
+	exec  AnyExecutor
+	err   error
+}
+
+func (r *panicRecorder) OnResolvePanic(scope *Scope, exec AnyExecutor, recovered any, stack []byte) error {
+	r.calls.Add(1)
+	r.exec = exec
+	return r.err
+}
+
+func TestResolveRecoversFactoryPanic(t *testing.T) {
+	recorder := &panicRecorder{BaseExtension: NewBaseExtension("panic-recorder")}
+	scope := NewScope(WithExtension(recorder))
+	defer scope.Dispose()
+
+	var cleaned atomic.Bool
+	broken := Provide(func(ctx *ResolveCtx) (int, error) {
+		ctx.OnCleanup(func() error {
+			cleaned.Store(true)
+			return nil
+		})
+		panic("boom")
+	})
+	dependent := Derive1(broken, func(ctx *ResolveCtx, b *Controller[int]) (int, error) {
+		return b.Get()
+	})
+
+	_, err := Resolve(scope, dependent)
+
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) {
+		t.Fatalf("expected ResolveError, got %v", err)
+	}
+	if resolveErr.Context != "panic" || !strings.Contains(resolveErr.Error(), "boom") {
+		t.Errorf("unexpected error %v", resolveErr)
+	}
+	if !strings.Contains(string(resolveErr.StackTrace), "panic_test.go") {
+		t.Error("expected the panicking factory in the stack trace")
+	}
+
+	if !cleaned.Load() {
+		t.Error("expected cleanups registered before the panic to run")
+	}
+	if Accessor(scope, broken).IsCached() || Accessor(scope, dependent).IsCached() {
+		t.Error("panicking executor must not be cached")
+	}
+	if recorder.calls.Load() != 1 || recorder.exec != AnyExecutor(broken) {
+		t.Errorf("expected one OnResolvePanic call for the broken executor, got %d", recorder.calls.Load())
+	}
+}
+
+func TestTransientFactoryPanic(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	broken := Provide(func(ctx *ResolveCtx) (int, error) {
+		panic(errors.New("bad input"))
+	}, WithLifetime(Transient))
+
+	_, err := Resolve(scope, broken)
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) || resolveErr.Context != "panic" {
+		t.Errorf("expected panic ResolveError, got %v", err)
+	}
+}
+
+func TestResolvePanicHookErrorKeepsContext(t *testing.T) {
+	hookErr := errors.New("report failed")
+	recorder := &panicRecorder{BaseExtension: NewBaseExtension("panic-recorder"), err: hookErr}
+	scope := NewScope(WithExtension(recorder))
+	defer scope.Dispose()
+
+	broken := Provide(func(ctx *ResolveCtx) (int, error) {
+		panic("boom")
+	})
+
+	_, err := Resolve(scope, broken)
+	resolveErr, ok := err.(*ResolveError)
+	if !ok || resolveErr.Context != "panic" {
+		t.Fatalf("expected the panic ResolveError as the outer error, got %v", err)
+	}
+	if !errors.Is(err, hookErr) || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the hook error attached to the panic, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package pumped
+
+import (
+	"context"
+	"fmt"
+)
+
+// presetBuilder is implemented by executors that can check a runtime preset
+// against their value type
+type presetBuilder interface {
+	buildPreset(replacement any) (preset, error)
+}
+
+func (e *Executor[T]) buildPreset(replacement any) (preset, error) {
+	return newPreset[T](replacement)
+}
+
+// SetPreset replaces exec with a value or another executor of the same type.
+// The cached value and its reactive dependents are invalidated and their
+// cleanups run, so the next resolution uses the preset. Extensions observe
+// the change as an OpPreset operation.
+func (s *Scope) SetPreset(exec AnyExecutor, replacement any) error {
+	builder, ok := exec.(presetBuilder)
+	if !ok {
+		return fmt.Errorf("executor %T does not support presets", exec)
+	}
+	p, err := builder.buildPreset(replacement)
+	if err != nil {
+		return err
+	}
+
+	return s.changePreset(exec, func() {
+		s.presets[exec] = p
+	})
+}
+
+// ClearPreset removes a preset set on this scope, invalidating exec so the
+// next resolution runs its own factory. It reports whether a preset was set.
+func (s *Scope) ClearPreset(exec AnyExecutor) (bool, error) {
+	s.mu.RLock()
+	_, ok := s.presets[exec]
+	s.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+
+	err := s.changePreset(exec, func() {
+		delete(s.presets, exec)
+	})
+	return err == nil, err
+}
+
+func (s *Scope) changePreset(exec AnyExecutor, apply func()) error {
+	s.mu.RLock()
+	exts := s.extensions
+	s.mu.RUnlock()
+
+	op := &Operation{
+		Kind:     OpPreset,
+		Executor: exec,
+		Scope:    s,
+	}
+	ctx := context.Background()
+
+	next := func() (any, error) {
+		s.mu.Lock()
+		apply()
+		s.mu.Unlock()
+
+		if s.overrideMemo != nil {
+			s.overrideMu.Lock()
+			clear(s.overrideMemo)
+			s.overrideMu.Unlock()
+		}
+
+		s.evict(exec)
+		return nil, nil
+	}
+
+	for i := len(exts) - 1; i >= 0; i-- {
+		ext := exts[i]
+		currentNext := next
+		next = func() (any, error) {
+			return ext.Wrap(ctx, currentNext, op)
+		}
+	}
+
+	// The error goes back to the caller; OnError is for resolution failures
+	_, err := next()
+	return err
+}
@@ -0,0 +1,130 @@
+package pumped
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+type presetRecorder struct {
+	BaseExtension
+	ops []OperationKind
+}
+
+func (r *presetRecorder) Wrap(ctx context.Context, next func() (any, error), op *Operation) (any, error) {
+	if op.Kind == OpPreset {
+		r.ops = append(r.ops, op.Kind)
+	}
+	return next()
+}
+
+func TestSetPresetInvalidatesDependents(t *testing.T) {
+	recorder := &presetRecorder{BaseExtension: NewBaseExtension("recorder")}
+	scope := NewScope(WithExtension(recorder))
+	defer scope.Dispose()
+
+	var cleaned atomic.Int32
+	flag := Provide(func(ctx *ResolveCtx) (bool, error) {
+		return false, nil
+	})
+	handler := Derive1(flag.Reactive(), func(ctx *ResolveCtx, f *Controller[bool]) (string, error) {
+		ctx.OnCleanup(func() error {
+			cleaned.Add(1)
+			return nil
+		})
+		on, _ := f.Get()
+		if on {
+			return "new", nil
+		}
+		return "old", nil
+	})
+
+	if got, _ := Resolve(scope, handler); got != "old" {
+		t.Fatalf("unexpected initial value %q", got)
+	}
+
+	if err := scope.SetPreset(flag, true); err != nil {
+		t.Fatalf("set preset failed: %v", err)
+	}
+	if cleaned.Load() != 1 {
+		t.Errorf("expected dependent cleanup on preset change, got %d", cleaned.Load())
+	}
+	if got, _ := Resolve(scope, handler); got != "new" {
+		t.Errorf("expected dependent to see the preset, got %q", got)
+	}
+
+	cleared, err := scope.ClearPreset(flag)
+	if err != nil || !cleared {
+		t.Fatalf("clear preset failed: %v, %v", cleared, err)
+	}
+	if got, _ := Resolve(scope, handler); got != "old" {
+		t.Errorf("expected original value after clearing, got %q", got)
+	}
+
+	if cleared, _ := scope.ClearPreset(flag); cleared {
+		t.Error("expected clearing a missing preset to report false")
+	}
+	if len(recorder.ops) != 2 {
+		t.Errorf("expected extensions to observe two preset operations, got %v", recorder.ops)
+	}
+}
+
+func TestSetPresetExecutorAndTypeMismatch(t *testing.T) {
+	scope := NewScope()
+	defer scope.Dispose()
+
+	real := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "real", nil
+	})
+	fake := Provide(func(ctx *ResolveCtx) (string, error) {
+		return "fake", nil
+	})
+
+	if err := scope.SetPreset(real, 42); err == nil {
+		t.Error("expected type mismatch error")
+	}
+
+	if err := scope.SetPreset(real, fake); err != nil {
+		t.Fatalf("set preset failed: %v", err)
+	}
+	if got, _ := Resolve(scope, real); got != "fake" {
+		t.Errorf("expected executor preset, got %q", got)
+	}
+}
+
+type presetVeto struct {
+	BaseExtension
+	errs int
+}
+
+func (v *presetVeto) Wrap(ctx context.Context, next func() (any, error), op *Operation) (any, error) {
+	if op.Kind == OpPreset {
+		return nil, errors.New("presets are locked")
+	}
+	return next()
+}
+
+func (v *presetVeto) OnError(err error, op *Operation, scope *Scope) {
+	v.errs++
+}
+
+func TestSetPresetRejectedByExtension(t *testing.T) {
+	veto := &presetVeto{BaseExtension: NewBaseExtension("veto")}
+	scope := NewScope(WithExtension(veto))
+	defer scope.Dispose()
+
+	flag := Provide(func(ctx *ResolveCtx) (bool, error) {
+		return false, nil
+	})
+
+	if err := scope.SetPreset(flag, true); err == nil {
+		t.Fatal("expected the extension to reject the preset")
+	}
+	if veto.errs != 0 {
+		t.Errorf("expected a rejected preset not to be reported as a resolution error, got %d", veto.errs)
+	}
+	if val, _ := Resolve(scope, flag); val {
+		t.Error("expected the rejected preset not to apply")
+	}
+}
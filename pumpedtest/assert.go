@@ -0,0 +1,152 @@
+package pumpedtest
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+// Counter counts calls of a wrapped function
+type Counter struct {
+	n atomic.Int64
+}
+
+// Count returns the number of calls so far
+func (c *Counter) Count() int {
+	return int(c.n.Load())
+}
+
+// Reset sets the count back to zero
+func (c *Counter) Reset() {
+	c.n.Store(0)
+}
+
+// Counted wraps a factory with a call counter, for counting across scopes
+// or factories that run outside extensions
+func Counted[T any](factory func(*pumped.ResolveCtx) (T, error)) (func(*pumped.ResolveCtx) (T, error), *Counter) {
+	c := &Counter{}
+	return func(ctx *pumped.ResolveCtx) (T, error) {
+		c.n.Add(1)
+		return factory(ctx)
+	}, c
+}
+
+// AssertFactoryCalls fails the test unless exec's factory ran want times
+func (s *Scope) AssertFactoryCalls(exec pumped.AnyExecutor, want int) {
+	s.t.Helper()
+	if got := s.FactoryCalls(exec); got != want {
+		s.t.Errorf("pumpedtest: expected %d factory call(s) for %v, got %d", want, exec, got)
+	}
+}
+
+// AssertCached fails the test unless the scope holds a value for exec
+func AssertCached[T any](s *Scope, exec *pumped.Executor[T]) {
+	s.t.Helper()
+	if !pumped.Accessor(s.Scope, exec).IsCached() {
+		s.t.Errorf("pumpedtest: expected %v to be cached", exec)
+	}
+}
+
+// AssertNotCached fails the test if the scope holds a value for exec
+func AssertNotCached[T any](s *Scope, exec *pumped.Executor[T]) {
+	s.t.Helper()
+	if pumped.Accessor(s.Scope, exec).IsCached() {
+		s.t.Errorf("pumpedtest: expected %v not to be cached", exec)
+	}
+}
+
+// AssertResolved fails the test unless exec was resolved or updated
+func (s *Scope) AssertResolved(exec pumped.AnyExecutor) {
+	s.t.Helper()
+	s.drainEvents()
+
+	s.mu.Lock()
+	n := s.resolved[exec]
+	s.mu.Unlock()
+	if n == 0 {
+		s.t.Errorf("pumpedtest: expected %v to be resolved", exec)
+	}
+}
+
+// AssertInvalidated fails the test unless exec was invalidated, by Update
+// of a reactive dependency, Release or a preset change
+func (s *Scope) AssertInvalidated(exec pumped.AnyExecutor) {
+	s.t.Helper()
+	s.drainEvents()
+
+	s.mu.Lock()
+	n := s.invalidated[exec]
+	s.mu.Unlock()
+	if n == 0 {
+		s.t.Errorf("pumpedtest: expected %v to be invalidated", exec)
+	}
+}
+
+// Node describes the expected shape of an execution tree. Empty fields
+// match anything; children match in any order.
+type Node struct {
+	Flow     string
+	Status   string
+	Children []Node
+}
+
+// AssertTree fails the test unless the execution rooted at rootID has the
+// expected shape
+func (s *Scope) AssertTree(rootID string, want Node) {
+	s.t.Helper()
+
+	tree := s.GetExecutionTree()
+	root := tree.GetNode(rootID)
+	if root == nil {
+		s.t.Errorf("pumpedtest: execution %q not found", rootID)
+		return
+	}
+	if diff := matchNode(tree, root, want, ""); diff != "" {
+		s.t.Errorf("pumpedtest: execution tree mismatch:\n%s", diff)
+	}
+}
+
+func matchNode(tree *pumped.ExecutionTree, node *pumped.ExecutionNode, want Node, path string) string {
+	snap := node.Snapshot()
+	path = path + "/" + snap.FlowName
+
+	var diffs []string
+	if want.Flow != "" && snap.FlowName != want.Flow {
+		diffs = append(diffs, fmt.Sprintf("  %s: flow %q, want %q", path, snap.FlowName, want.Flow))
+	}
+	if want.Status != "" && snap.Status.String() != want.Status {
+		diffs = append(diffs, fmt.Sprintf("  %s: status %s, want %s", path, snap.Status, want.Status))
+	}
+
+	children := tree.GetChildren(node.ID)
+	if len(children) != len(want.Children) {
+		diffs = append(diffs, fmt.Sprintf("  %s: %d child execution(s), want %d", path, len(children), len(want.Children)))
+		return strings.Join(diffs, "\n")
+	}
+
+	used := make([]bool, len(children))
+	for _, wantChild := range want.Children {
+		found := false
+		var firstDiff string
+		for i, child := range children {
+			if used[i] {
+				continue
+			}
+			diff := matchNode(tree, child, wantChild, path)
+			if diff == "" {
+				used[i] = true
+				found = true
+				break
+			}
+			if firstDiff == "" {
+				firstDiff = diff
+			}
+		}
+		if !found {
+			diffs = append(diffs, firstDiff)
+		}
+	}
+	return strings.Join(diffs, "\n")
+}
@@ -0,0 +1,276 @@
+// Package pumpedtest provides test helpers for pumped scopes: scopes that
+// dispose themselves when the test ends, factory call counters, assertions
+// on executor state and execution trees, and leak detection.
+//
+// Usage:
+//
+//	func TestCheckout(t *testing.T) {
+//	    scope := pumpedtest.NewTestScope(t, pumped.WithPreset(db, fakeDB))
+//
+//	    _, execCtx, err := pumped.Exec(scope.Scope, ctx, checkout)
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//
+//	    scope.AssertFactoryCalls(db, 0)
+//	    scope.AssertTree(execCtx.ID(), pumpedtest.Node{
+//	        Flow: "checkout",
+//	        Children: []pumpedtest.Node{{Flow: "charge"}},
+//	    })
+//	}
+//
+// When the test ends the scope is disposed and the test fails if a cleanup
+// returned an error no extension handled, a tracked cleanup never ran, a
+// singleton was resolved after dispose, so its cleanups never run, or a
+// flow is still running.
+package pumpedtest
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+// DefaultDrainTimeout is how long a test scope waits for running flows
+// before reporting them as leaked
+const DefaultDrainTimeout = time.Second
+
+// Scope is a pumped scope bound to a test
+type Scope struct {
+	*pumped.Scope
+
+	t        testing.TB
+	recorder *recorder
+	events   *pumped.EventSubscription
+
+	mu            sync.Mutex
+	drainTimeout  time.Duration
+	allowCleanErr bool
+	tracked       []*trackedCleanup
+	resolved      map[pumped.AnyExecutor]int
+	invalidated   map[pumped.AnyExecutor]int
+	dropped       uint64
+}
+
+type trackedCleanup struct {
+	name string
+	ran  bool
+}
+
+// NewTestScope creates a scope that is disposed and checked for leaks when
+// the test ends
+func NewTestScope(t testing.TB, opts ...pumped.ScopeOption) *Scope {
+	t.Helper()
+
+	rec := &recorder{
+		BaseExtension: pumped.NewBaseExtension("pumpedtest"),
+		calls:         make(map[pumped.AnyExecutor]int),
+	}
+	opts = append(opts, pumped.WithExtension(rec))
+
+	s := &Scope{
+		Scope:        pumped.NewScope(opts...),
+		t:            t,
+		recorder:     rec,
+		drainTimeout: DefaultDrainTimeout,
+		resolved:     make(map[pumped.AnyExecutor]int),
+		invalidated:  make(map[pumped.AnyExecutor]int),
+	}
+	s.events = s.Events(context.Background(),
+		pumped.EventKinds(pumped.EventExecutorResolved, pumped.EventExecutorUpdated, pumped.EventExecutorInvalidated),
+		pumped.EventBuffer(1<<16),
+	)
+
+	t.Cleanup(s.finish)
+	return s
+}
+
+// SetDrainTimeout changes how long the scope waits for running flows at the
+// end of the test
+func (s *Scope) SetDrainTimeout(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drainTimeout = d
+}
+
+// AllowCleanupErrors stops unhandled cleanup errors from failing the test,
+// for tests that exercise failing cleanups
+func (s *Scope) AllowCleanupErrors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.allowCleanErr = true
+}
+
+// CleanupErrors returns the cleanup errors no other extension handled
+func (s *Scope) CleanupErrors() []*pumped.CleanupError {
+	return s.recorder.cleanupErrors()
+}
+
+// TrackCleanup wraps a cleanup so the test fails if it has not run by the
+// time the scope is disposed
+func (s *Scope) TrackCleanup(name string, fn func() error) func() error {
+	tc := &trackedCleanup{name: name}
+
+	s.mu.Lock()
+	s.tracked = append(s.tracked, tc)
+	s.mu.Unlock()
+
+	return func() error {
+		s.mu.Lock()
+		tc.ran = true
+		s.mu.Unlock()
+		return fn()
+	}
+}
+
+// FactoryCalls returns how many times exec's factory ran in this scope
+func (s *Scope) FactoryCalls(exec pumped.AnyExecutor) int {
+	return s.recorder.factoryCalls(exec)
+}
+
+func (s *Scope) finish() {
+	s.t.Helper()
+
+	// Mark before disposing: cleanups that resolve singletons register
+	// cleanups of their own that Dispose no longer runs
+	s.recorder.markDisposed()
+	if err := s.Dispose(); err != nil {
+		s.t.Errorf("pumpedtest: dispose failed: %v", err)
+	}
+	s.events.Close()
+
+	s.mu.Lock()
+	drain := s.drainTimeout
+	allowCleanErr := s.allowCleanErr
+	tracked := s.tracked
+	s.mu.Unlock()
+
+	if !allowCleanErr {
+		for _, cerr := range s.recorder.cleanupErrors() {
+			s.t.Errorf("pumpedtest: unhandled cleanup error (%s): %v", cerr.Context, cerr.Err)
+		}
+	}
+
+	s.mu.Lock()
+	for _, tc := range tracked {
+		if !tc.ran {
+			s.t.Errorf("pumpedtest: cleanup %q never ran", tc.name)
+		}
+	}
+	s.mu.Unlock()
+
+	deadline := time.Now().Add(drain)
+	for s.FlowStats().InFlight > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if stats := s.FlowStats(); stats.InFlight > 0 {
+		s.t.Errorf("pumpedtest: %d flow(s) still running at test end (%d abandoned)", stats.InFlight, stats.Abandoned)
+	}
+
+	for _, exec := range s.recorder.lateResolves() {
+		s.t.Errorf("pumpedtest: executor %v resolved after dispose; its cleanups never run", exec)
+	}
+}
+
+// drainEvents records executor events published so far. Events are sent
+// synchronously into the subscription buffer, so everything that happened
+// before the call is observed unless the buffer overflowed, which fails
+// the test.
+func (s *Scope) drainEvents() {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dropped := s.events.Dropped(); dropped > s.dropped {
+		s.t.Errorf("pumpedtest: %d executor event(s) dropped; resolved and invalidated assertions are unreliable", dropped-s.dropped)
+		s.dropped = dropped
+	}
+
+	for {
+		select {
+		case ev, ok := <-s.events.C:
+			if !ok {
+				return
+			}
+			switch ev.Kind {
+			case pumped.EventExecutorResolved, pumped.EventExecutorUpdated:
+				s.resolved[ev.Executor]++
+			case pumped.EventExecutorInvalidated:
+				s.invalidated[ev.Executor]++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// recorder counts factory runs, collects unhandled cleanup errors and
+// notes singletons resolved after dispose. It orders last, so it wraps
+// factories innermost and only sees cleanup errors other extensions
+// declined.
+type recorder struct {
+	pumped.BaseExtension
+
+	mu        sync.Mutex
+	calls     map[pumped.AnyExecutor]int
+	cleanErrs []*pumped.CleanupError
+	disposed  bool
+	late      []pumped.AnyExecutor
+}
+
+func (r *recorder) Order() int {
+	return math.MaxInt
+}
+
+func (r *recorder) Wrap(ctx context.Context, next func() (any, error), op *pumped.Operation) (any, error) {
+	if op.Kind == pumped.OpResolve && op.Executor != nil {
+		r.mu.Lock()
+		r.calls[op.Executor]++
+		if r.disposed && pumped.LifetimeOf(op.Executor) == pumped.Singleton {
+			r.late = append(r.late, op.Executor)
+		}
+		r.mu.Unlock()
+	}
+	return next()
+}
+
+// Dispose marks the scope disposed when a test disposes it itself
+func (r *recorder) Dispose(scope *pumped.Scope) error {
+	r.markDisposed()
+	return nil
+}
+
+func (r *recorder) markDisposed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disposed = true
+}
+
+func (r *recorder) lateResolves() []pumped.AnyExecutor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]pumped.AnyExecutor(nil), r.late...)
+}
+
+func (r *recorder) OnCleanupError(err *pumped.CleanupError) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cleanErrs = append(r.cleanErrs, err)
+	return false
+}
+
+func (r *recorder) factoryCalls(exec pumped.AnyExecutor) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls[exec]
+}
+
+func (r *recorder) cleanupErrors() []*pumped.CleanupError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*pumped.CleanupError(nil), r.cleanErrs...)
+}
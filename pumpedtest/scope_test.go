@@ -0,0 +1,191 @@
+package pumpedtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pumped "github.com/pumped-fn/pumped-go"
+)
+
+// fakeT records failures instead of failing the real test
+type fakeT struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeT) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func (f *fakeT) failedWith(substr string) bool {
+	for _, msg := range f.errors {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestScopeAssertions(t *testing.T) {
+	scope := NewTestScope(t)
+
+	factory, counter := Counted(func(ctx *pumped.ResolveCtx) (int, error) {
+		return 1, nil
+	})
+	base := pumped.Provide(factory)
+	derived := pumped.Derive1(base.Reactive(), func(ctx *pumped.ResolveCtx, b *pumped.Controller[int]) (int, error) {
+		v, err := b.Get()
+		return v + 1, err
+	})
+
+	if _, err := pumped.Resolve(scope.Scope, derived); err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	_, _ = pumped.Resolve(scope.Scope, derived)
+
+	scope.AssertFactoryCalls(base, 1)
+	AssertCached(scope, derived)
+	scope.AssertResolved(base)
+	if counter.Count() != 1 {
+		t.Errorf("expected counted factory to run once, got %d", counter.Count())
+	}
+
+	if err := pumped.Update(context.Background(), scope.Scope, base, 5); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	scope.AssertInvalidated(derived)
+	AssertNotCached(scope, derived)
+}
+
+func TestScopeAssertTree(t *testing.T) {
+	scope := NewTestScope(t)
+
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	child := pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (int, error) {
+		return 1, nil
+	}, pumped.WithFlowTag(pumped.FlowName(), "child"))
+	parent := pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (int, error) {
+		a, _, _ := pumped.Exec1(execCtx, child)
+		b, _, _ := pumped.Exec1(execCtx, child)
+		return a + b, nil
+	}, pumped.WithFlowTag(pumped.FlowName(), "parent"))
+
+	_, execCtx, err := pumped.Exec(scope.Scope, context.Background(), parent)
+	if err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+
+	scope.AssertTree(execCtx.ID(), Node{
+		Flow:   "parent",
+		Status: "success",
+		Children: []Node{
+			{Flow: "child"},
+			{Flow: "child"},
+		},
+	})
+
+	ft := &fakeT{}
+	probe := &Scope{Scope: scope.Scope, t: ft}
+	probe.AssertTree(execCtx.ID(), Node{Flow: "parent", Children: []Node{{Flow: "other"}}})
+	if !ft.failedWith("child execution(s)") {
+		t.Errorf("expected shape mismatch, got %v", ft.errors)
+	}
+}
+
+func TestScopeReportsLeaks(t *testing.T) {
+	ft := &fakeT{}
+	scope := NewTestScope(ft)
+	scope.SetDrainTimeout(10 * time.Millisecond)
+
+	res := pumped.Provide(func(ctx *pumped.ResolveCtx) (int, error) {
+		ctx.OnCleanup(func() error {
+			return errors.New("close failed")
+		})
+		return 1, nil
+	})
+	_, _ = pumped.Resolve(scope.Scope, res)
+
+	_ = scope.TrackCleanup("temp dir", func() error { return nil })
+
+	late := pumped.Provide(func(ctx *pumped.ResolveCtx) (int, error) {
+		return 2, nil
+	})
+	reopens := pumped.Provide(func(ctx *pumped.ResolveCtx) (int, error) {
+		ctx.OnCleanup(func() error {
+			_, err := pumped.Resolve(scope.Scope, late)
+			return err
+		})
+		return 3, nil
+	})
+	_, _ = pumped.Resolve(scope.Scope, reopens)
+
+	release := make(chan struct{})
+	unit := pumped.Provide(func(ctx *pumped.ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	stuck := pumped.Flow1(unit, func(execCtx *pumped.ExecutionCtx, _ *pumped.Controller[struct{}]) (int, error) {
+		<-release
+		return 0, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, _, _ = pumped.Exec(scope.Scope, ctx, stuck)
+
+	ft.finish()
+	close(release)
+
+	for _, want := range []string{"close failed", `"temp dir" never ran`, "still running", "resolved after dispose"} {
+		if !ft.failedWith(want) {
+			t.Errorf("expected failure mentioning %q, got %v", want, ft.errors)
+		}
+	}
+}
+
+func TestScopeReportsDroppedEvents(t *testing.T) {
+	scope := NewTestScope(t)
+
+	ft := &fakeT{}
+	probe := &Scope{
+		Scope:       scope.Scope,
+		t:           ft,
+		events:      scope.Events(context.Background(), pumped.EventBuffer(1)),
+		resolved:    make(map[pumped.AnyExecutor]int),
+		invalidated: make(map[pumped.AnyExecutor]int),
+	}
+	defer probe.events.Close()
+
+	var last *pumped.Executor[int]
+	for i := 0; i < 3; i++ {
+		last = pumped.Provide(func(ctx *pumped.ResolveCtx) (int, error) {
+			return i, nil
+		})
+		_, _ = pumped.Resolve(scope.Scope, last)
+	}
+
+	probe.AssertResolved(last)
+	if !ft.failedWith("dropped") {
+		t.Errorf("expected dropped events to fail the test, got %v", ft.errors)
+	}
+}
@@ -0,0 +1,676 @@
+package pumped
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoJobQueue is returned by Enqueue on scopes created without WithJobQueue
+	ErrNoJobQueue = errors.New("scope has no job queue")
+	// ErrJobNotFound is returned when a job ID is unknown
+	ErrJobNotFound = errors.New("job not found")
+	// ErrQueueStopped is returned by Enqueue after the scope is disposed
+	ErrQueueStopped = errors.New("job queue stopped")
+	// ErrQueueUnavailable is returned by Enqueue when the queue failed to
+	// start, wrapping the cause
+	ErrQueueUnavailable = errors.New("job queue unavailable")
+)
+
+var queueJobIDTag = NewTag[string]("queue.job_id")
+
+// QueueJobID is set on executions started by the job queue to the job ID
+func QueueJobID() Tag[string] { return queueJobIDTag }
+
+// JobState is the lifecycle state of a queued job
+type JobState string
+
+const (
+	JobPending   JobState = "pending"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobDead      JobState = "dead"
+)
+
+// QueuedJob is the persisted record of a job submitted with Enqueue.
+// Input is the JSON encoding of the value passed to Enqueue.
+type QueuedJob struct {
+	ID          string          `json:"id"`
+	FlowName    string          `json:"flow_name"`
+	Input       json.RawMessage `json:"input,omitempty"`
+	Priority    int             `json:"priority"`
+	State       JobState        `json:"state"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	ExecutionID string          `json:"execution_id,omitempty"`
+	Seq         uint64          `json:"seq"`
+	EnqueuedAt  time.Time       `json:"enqueued_at"`
+	RunAt       time.Time       `json:"run_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// RetryPolicy decides how often a failing job runs and how long it waits
+// between attempts
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     func(attempt int) time.Duration
+}
+
+// ExponentialBackoff doubles the delay after every attempt, starting at
+// base and capped at max
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// QueueOption configures the job queue of a scope
+type QueueOption func(*queueConfig)
+
+type queueConfig struct {
+	workers     int
+	store       JobStore
+	retry       RetryPolicy
+	flows       []AnyFlow
+	concurrency map[string]int
+	deadLetter  func(QueuedJob)
+	history     int
+}
+
+// WithQueueWorkers sets the size of the worker pool (default 4)
+func WithQueueWorkers(n int) QueueOption {
+	return func(cfg *queueConfig) {
+		cfg.workers = n
+	}
+}
+
+// WithQueueStore sets where jobs are persisted (default in memory)
+func WithQueueStore(store JobStore) QueueOption {
+	return func(cfg *queueConfig) {
+		cfg.store = store
+	}
+}
+
+// WithQueueRetry sets the default retry policy (default a single attempt)
+func WithQueueRetry(policy RetryPolicy) QueueOption {
+	return func(cfg *queueConfig) {
+		cfg.retry = policy
+	}
+}
+
+// WithQueueFlows registers flows by name so that jobs loaded from the store
+// after a restart can run before the flow is enqueued again
+func WithQueueFlows(flows ...AnyFlow) QueueOption {
+	return func(cfg *queueConfig) {
+		cfg.flows = append(cfg.flows, flows...)
+	}
+}
+
+// WithFlowConcurrency limits how many jobs of the named flow run at once
+func WithFlowConcurrency(flowName string, n int) QueueOption {
+	return func(cfg *queueConfig) {
+		if cfg.concurrency == nil {
+			cfg.concurrency = make(map[string]int)
+		}
+		cfg.concurrency[flowName] = n
+	}
+}
+
+// WithQueueHistory sets how many succeeded jobs Job still reports after
+// they are removed from the queue (default 1000)
+func WithQueueHistory(n int) QueueOption {
+	return func(cfg *queueConfig) {
+		cfg.history = n
+	}
+}
+
+// WithDeadLetterHandler is called with jobs that exhausted their attempts.
+// It runs on the worker that ran the last attempt, so disposing the scope
+// waits for it.
+func WithDeadLetterHandler(fn func(QueuedJob)) QueueOption {
+	return func(cfg *queueConfig) {
+		cfg.deadLetter = fn
+	}
+}
+
+// WithJobQueue returns an option that gives the scope a background job
+// queue. Jobs left pending or running in the store are resumed when the
+// scope is created. If the store cannot be loaded or a flow passed to
+// WithQueueFlows is unnamed, the queue does not start: the failure is
+// reported to extensions as a CleanupError with context "queue" and Enqueue
+// returns it wrapped in ErrQueueUnavailable.
+func WithJobQueue(opts ...QueueOption) ScopeOption {
+	cfg := queueConfig{
+		workers: 4,
+		retry:   RetryPolicy{MaxAttempts: 1},
+		history: 1000,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.store == nil {
+		cfg.store = NewMemoryJobStore()
+	}
+
+	return func(s *Scope) {
+		s.queue = newJobQueue(s, cfg)
+	}
+}
+
+// EnqueueOption configures a single job
+type EnqueueOption func(*QueuedJob)
+
+// WithPriority runs the job before pending jobs with a lower priority
+func WithPriority(p int) EnqueueOption {
+	return func(job *QueuedJob) {
+		job.Priority = p
+	}
+}
+
+// WithMaxAttempts overrides the queue's retry policy attempts for one job
+func WithMaxAttempts(n int) EnqueueOption {
+	return func(job *QueuedJob) {
+		job.MaxAttempts = n
+	}
+}
+
+// WithRunAt delays the first attempt until t
+func WithRunAt(t time.Time) EnqueueOption {
+	return func(job *QueuedJob) {
+		job.RunAt = t
+	}
+}
+
+// queueRunner executes a flow without knowing its result type
+type queueRunner interface {
+	execQueued(s *Scope, ctx context.Context, opts ...ExecOption) (*ExecutionCtx, error)
+}
+
+func (f *Flow[R]) execQueued(s *Scope, ctx context.Context, opts ...ExecOption) (*ExecutionCtx, error) {
+	_, execCtx, err := Exec(s, ctx, f, opts...)
+	return execCtx, err
+}
+
+type jobQueue struct {
+	scope *Scope
+	cfg   queueConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wake   chan struct{}
+	work   chan *queueEntry
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	flows   map[string]queueRunner
+	jobs    map[string]*queueEntry // pending and running
+	ready   map[string]*readyJobs  // due pending jobs by flow name
+	delayed delayedJobs            // pending jobs waiting for RunAt
+	running map[string]int
+	idle    int
+	seq     uint64
+	stopped bool
+
+	deadJobs map[string]QueuedJob
+	finished map[string]QueuedJob
+	history  []string // finished job IDs, oldest first
+
+	// storeMu orders store writes. It is taken before q.mu is released so
+	// writes reach the store in the order of the state changes, without
+	// holding q.mu during I/O.
+	storeMu sync.Mutex
+
+	// err is set when the queue could not start
+	err error
+}
+
+type queueEntry struct {
+	job   QueuedJob
+	input any
+}
+
+func newJobQueue(s *Scope, cfg queueConfig) *jobQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &jobQueue{
+		scope:   s,
+		cfg:     cfg,
+		ctx:     ctx,
+		cancel:  cancel,
+		wake:    make(chan struct{}, 1),
+		work:    make(chan *queueEntry, cfg.workers),
+		flows:   make(map[string]queueRunner),
+		jobs:    make(map[string]*queueEntry),
+		ready:   make(map[string]*readyJobs),
+		running: make(map[string]int),
+		idle:    cfg.workers,
+
+		deadJobs: make(map[string]QueuedJob),
+		finished: make(map[string]QueuedJob),
+	}
+	for _, flow := range cfg.flows {
+		if err := q.register(flow); err != nil {
+			q.err = err
+			break
+		}
+	}
+	return q
+}
+
+// start resumes stored jobs and starts the workers. A queue that fails to
+// start reports the error and refuses new jobs.
+func (q *jobQueue) start() {
+	if q.err == nil {
+		q.err = q.load()
+	}
+	if q.err != nil {
+		q.scope.handleCleanupError(&CleanupError{
+			Err:     q.err,
+			Context: "queue",
+		})
+		return
+	}
+
+	for i := 0; i < q.cfg.workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+	q.wg.Add(1)
+	go q.dispatch()
+}
+
+func (q *jobQueue) load() error {
+	loaded, err := q.cfg.store.Load()
+	if err != nil {
+		return fmt.Errorf("loading job queue: %w", err)
+	}
+
+	for _, job := range loaded {
+		if job.Seq > q.seq {
+			q.seq = job.Seq
+		}
+		switch job.State {
+		case JobDead:
+			q.deadJobs[job.ID] = job
+			continue
+		case JobSucceeded:
+			continue
+		case JobRunning:
+			// Interrupted by a shutdown; the attempt did not complete
+			job.State = JobPending
+			job.Attempts--
+		}
+		entry := &queueEntry{job: job, input: job.Input}
+		q.jobs[job.ID] = entry
+		q.schedule(entry)
+	}
+	return nil
+}
+
+func (q *jobQueue) stop() {
+	q.mu.Lock()
+	if q.stopped {
+		q.mu.Unlock()
+		return
+	}
+	q.stopped = true
+	q.mu.Unlock()
+
+	q.cancel()
+	q.wg.Wait()
+}
+
+func (q *jobQueue) register(flow AnyFlow) error {
+	runner, ok := flow.(queueRunner)
+	if !ok {
+		return fmt.Errorf("job queue: unsupported flow type %T", flow)
+	}
+	name, _ := flow.GetTag(flowNameTag)
+	flowName, _ := name.(string)
+	if flowName == "" {
+		return errors.New("job queue: flows must be named with FlowName to be enqueued")
+	}
+	q.flows[flowName] = runner
+	return nil
+}
+
+func (q *jobQueue) enqueue(flow AnyFlow, input any, opts ...EnqueueOption) (string, error) {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("encoding job input: %w", err)
+	}
+
+	q.mu.Lock()
+	if q.err != nil {
+		q.mu.Unlock()
+		return "", fmt.Errorf("%w: %w", ErrQueueUnavailable, q.err)
+	}
+	if q.stopped {
+		q.mu.Unlock()
+		return "", ErrQueueStopped
+	}
+	if err := q.register(flow); err != nil {
+		q.mu.Unlock()
+		return "", err
+	}
+
+	name, _ := flow.GetTag(flowNameTag)
+	now := time.Now()
+	q.seq++
+	job := QueuedJob{
+		ID:          fmt.Sprintf("job-%d-%d", now.UnixNano(), q.seq),
+		FlowName:    name.(string),
+		Input:       raw,
+		State:       JobPending,
+		MaxAttempts: q.cfg.retry.MaxAttempts,
+		Seq:         q.seq,
+		EnqueuedAt:  now,
+		RunAt:       now,
+		UpdatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(&job)
+	}
+
+	// The job only becomes visible to workers once it is persisted
+	q.storeMu.Lock()
+	q.mu.Unlock()
+	err = q.cfg.store.Save(job)
+	q.storeMu.Unlock()
+	if err != nil {
+		return "", fmt.Errorf("persisting job: %w", err)
+	}
+
+	q.mu.Lock()
+	entry := &queueEntry{job: job, input: input}
+	q.jobs[job.ID] = entry
+	q.schedule(entry)
+	q.signal()
+	q.mu.Unlock()
+
+	return job.ID, nil
+}
+
+func (q *jobQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *jobQueue) dispatch() {
+	defer q.wg.Done()
+	defer close(q.work)
+
+	for {
+		var wakeAt time.Time
+		var started []*queueEntry
+		var writes []storeWrite
+
+		q.mu.Lock()
+		for q.idle > 0 {
+			var entry *queueEntry
+			entry, wakeAt = q.next(time.Now())
+			if entry == nil {
+				break
+			}
+			q.idle--
+			q.running[entry.job.FlowName]++
+			entry.job.State = JobRunning
+			entry.job.Attempts++
+			entry.job.UpdatedAt = time.Now()
+			started = append(started, entry)
+			writes = append(writes, storeWrite{job: entry.job})
+		}
+		q.unlockAndStore(writes...)
+
+		for _, entry := range started {
+			q.work <- entry
+		}
+
+		var timer <-chan time.Time
+		if !wakeAt.IsZero() {
+			timer = time.After(time.Until(wakeAt))
+		}
+
+		select {
+		case <-q.wake:
+		case <-timer:
+		case <-q.ctx.Done():
+			return
+		}
+	}
+}
+
+func (q *jobQueue) worker() {
+	defer q.wg.Done()
+
+	for entry := range q.work {
+		q.run(entry)
+	}
+}
+
+func (q *jobQueue) run(entry *queueEntry) {
+	q.mu.Lock()
+	runner := q.flows[entry.job.FlowName]
+	job := entry.job
+	q.mu.Unlock()
+
+	execCtx, err := runner.execQueued(q.scope, q.ctx,
+		WithExecTag(inputTag, entry.input),
+		WithExecTag(queueJobIDTag, job.ID),
+	)
+
+	q.mu.Lock()
+
+	q.idle++
+	q.running[job.FlowName]--
+	q.signal()
+
+	job = entry.job
+	if execCtx != nil {
+		job.ExecutionID = execCtx.ID()
+	}
+	job.UpdatedAt = time.Now()
+
+	switch {
+	case err == nil:
+		job.State = JobSucceeded
+		job.LastError = ""
+		q.finish(job)
+		q.unlockAndStore(storeWrite{job: job, delete: true})
+		return
+	case q.ctx.Err() != nil:
+		// Shutting down: leave the job for the next start
+		job.State = JobPending
+		job.Attempts--
+		job.LastError = err.Error()
+		entry.job = job
+		q.schedule(entry)
+		q.unlockAndStore(storeWrite{job: job})
+		return
+	}
+
+	job.LastError = err.Error()
+	if job.Attempts < job.MaxAttempts {
+		job.State = JobPending
+		if q.cfg.retry.Backoff != nil {
+			job.RunAt = job.UpdatedAt.Add(q.cfg.retry.Backoff(job.Attempts))
+		}
+		entry.job = job
+		q.schedule(entry)
+		q.unlockAndStore(storeWrite{job: job})
+		return
+	}
+
+	job.State = JobDead
+	delete(q.jobs, job.ID)
+	q.deadJobs[job.ID] = job
+	q.unlockAndStore(storeWrite{job: job})
+	if q.cfg.deadLetter != nil {
+		q.cfg.deadLetter(job)
+	}
+}
+
+// finish moves a succeeded job out of the queue into the bounded history.
+// Callers hold q.mu.
+func (q *jobQueue) finish(job QueuedJob) {
+	delete(q.jobs, job.ID)
+	if q.cfg.history <= 0 {
+		return
+	}
+	q.finished[job.ID] = job
+	q.history = append(q.history, job.ID)
+	for len(q.history) > q.cfg.history {
+		delete(q.finished, q.history[0])
+		q.history = q.history[1:]
+	}
+}
+
+type storeWrite struct {
+	job    QueuedJob
+	delete bool
+}
+
+// unlockAndStore releases q.mu and applies writes to the store, reporting
+// failures to extensions. Callers hold q.mu.
+func (q *jobQueue) unlockAndStore(writes ...storeWrite) {
+	if len(writes) == 0 {
+		q.mu.Unlock()
+		return
+	}
+
+	q.storeMu.Lock()
+	defer q.storeMu.Unlock()
+	q.mu.Unlock()
+
+	for _, w := range writes {
+		var err error
+		if w.delete {
+			err = q.cfg.store.Delete(w.job.ID)
+		} else {
+			err = q.cfg.store.Save(w.job)
+		}
+		if err != nil {
+			q.storeError(w.job, err)
+		}
+	}
+}
+
+func (q *jobQueue) storeError(job QueuedJob, err error) {
+	q.scope.handleCleanupError(&CleanupError{
+		ExecutionID: job.ExecutionID,
+		Err:         fmt.Errorf("job %s: %w", job.ID, err),
+		Context:     "queue",
+	})
+}
+
+func (q *jobQueue) get(id string) (QueuedJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if entry, ok := q.jobs[id]; ok {
+		return entry.job, true
+	}
+	if job, ok := q.deadJobs[id]; ok {
+		return job, true
+	}
+	job, ok := q.finished[id]
+	return job, ok
+}
+
+func (q *jobQueue) dead() []QueuedJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var jobs []QueuedJob
+	for _, job := range q.deadJobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Seq < jobs[j].Seq })
+	return jobs
+}
+
+// Enqueue submits flow for asynchronous execution by the scope's job queue
+// and returns the job ID. The flow must be named with FlowName. Input is
+// available to the flow through the Input tag and JobInput, and must be
+// JSON-encodable so the job can be persisted.
+func (s *Scope) Enqueue(flow AnyFlow, input any, opts ...EnqueueOption) (string, error) {
+	q := s.root().queue
+	if q == nil {
+		return "", ErrNoJobQueue
+	}
+	return q.enqueue(flow, input, opts...)
+}
+
+// Job returns the current state of a queued job. Succeeded jobs are only
+// reported while they are among the last WithQueueHistory to finish.
+func (s *Scope) Job(id string) (QueuedJob, error) {
+	q := s.root().queue
+	if q == nil {
+		return QueuedJob{}, ErrNoJobQueue
+	}
+	job, ok := q.get(id)
+	if !ok {
+		return QueuedJob{}, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return job, nil
+}
+
+// JobExecution returns the execution node of a job's latest attempt
+func (s *Scope) JobExecution(id string) (*ExecutionNode, bool) {
+	job, err := s.Job(id)
+	if err != nil || job.ExecutionID == "" {
+		return nil, false
+	}
+	node := s.execTree.GetNode(job.ExecutionID)
+	return node, node != nil
+}
+
+// DeadLetters returns the jobs that exhausted their attempts
+func (s *Scope) DeadLetters() []QueuedJob {
+	q := s.root().queue
+	if q == nil {
+		return nil
+	}
+	return q.dead()
+}
+
+// JobInput returns the input of a queued job as T. Jobs resumed from a
+// store carry their input as JSON, which is decoded into T.
+func JobInput[T any](execCtx *ExecutionCtx) (T, error) {
+	var zero T
+
+	val, ok := execCtx.Get(inputTag)
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", ErrTagNotFound, inputTag.Key())
+	}
+	switch v := val.(type) {
+	case T:
+		return v, nil
+	case json.RawMessage:
+		var out T
+		if err := json.Unmarshal(v, &out); err != nil {
+			return zero, fmt.Errorf("decoding job input: %w", err)
+		}
+		return out, nil
+	default:
+		return zero, &TagTypeError{
+			Key:      inputTag.Key(),
+			Expected: fmt.Sprintf("%T", zero),
+			Actual:   fmt.Sprintf("%T", val),
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package pumped
+
+import (
+	"container/heap"
+	"time"
+)
+
+// readyJobs is a heap of runnable jobs, highest priority first and in
+// enqueue order within a priority
+type readyJobs []*queueEntry
+
+func (h readyJobs) Len() int { return len(h) }
+
+func (h readyJobs) Less(i, j int) bool { return runsBefore(&h[i].job, &h[j].job) }
+
+func (h readyJobs) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *readyJobs) Push(x any) { *h = append(*h, x.(*queueEntry)) }
+
+func (h *readyJobs) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
+
+// delayedJobs is a heap of jobs waiting for their RunAt, earliest first
+type delayedJobs []*queueEntry
+
+func (h delayedJobs) Len() int { return len(h) }
+
+func (h delayedJobs) Less(i, j int) bool {
+	a, b := &h[i].job, &h[j].job
+	if !a.RunAt.Equal(b.RunAt) {
+		return a.RunAt.Before(b.RunAt)
+	}
+	return a.Seq < b.Seq
+}
+
+func (h delayedJobs) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *delayedJobs) Push(x any) { *h = append(*h, x.(*queueEntry)) }
+
+func (h *delayedJobs) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
+
+func runsBefore(a, b *QueuedJob) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.Seq < b.Seq
+}
+
+// schedule queues a pending entry for dispatch: jobs due now go to their
+// flow's ready heap, later ones to the delayed heap. Callers hold q.mu.
+func (q *jobQueue) schedule(entry *queueEntry) {
+	if entry.job.RunAt.After(time.Now()) {
+		heap.Push(&q.delayed, entry)
+		return
+	}
+	q.makeReady(entry)
+}
+
+func (q *jobQueue) makeReady(entry *queueEntry) {
+	ready := q.ready[entry.job.FlowName]
+	if ready == nil {
+		ready = &readyJobs{}
+		q.ready[entry.job.FlowName] = ready
+	}
+	heap.Push(ready, entry)
+}
+
+// next pops the runnable job with the highest priority among flows that are
+// registered and under their concurrency limit, and reports when the
+// earliest delayed job becomes due. Callers hold q.mu.
+func (q *jobQueue) next(now time.Time) (*queueEntry, time.Time) {
+	for len(q.delayed) > 0 && !q.delayed[0].job.RunAt.After(now) {
+		q.makeReady(heap.Pop(&q.delayed).(*queueEntry))
+	}
+	var wakeAt time.Time
+	if len(q.delayed) > 0 {
+		wakeAt = q.delayed[0].job.RunAt
+	}
+
+	var best *readyJobs
+	for name, ready := range q.ready {
+		if len(*ready) == 0 {
+			continue
+		}
+		if _, ok := q.flows[name]; !ok {
+			continue
+		}
+		if limit, ok := q.cfg.concurrency[name]; ok && q.running[name] >= limit {
+			continue
+		}
+		if best == nil || runsBefore(&(*ready)[0].job, &(*best)[0].job) {
+			best = ready
+		}
+	}
+	if best == nil {
+		return nil, wakeAt
+	}
+	return heap.Pop(best).(*queueEntry), wakeAt
+}
@@ -0,0 +1,282 @@
+package pumped
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// JobStore persists queued jobs. Save inserts or replaces a job by ID.
+// Implementations must be safe for concurrent use.
+type JobStore interface {
+	Save(job QueuedJob) error
+	Delete(id string) error
+	Load() ([]QueuedJob, error)
+}
+
+type memoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]QueuedJob
+}
+
+// NewMemoryJobStore returns a store that keeps jobs in memory. Jobs do not
+// survive a restart.
+func NewMemoryJobStore() JobStore {
+	return &memoryJobStore{jobs: make(map[string]QueuedJob)}
+}
+
+func (m *memoryJobStore) Save(job QueuedJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = job
+	return nil
+}
+
+func (m *memoryJobStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.jobs, id)
+	return nil
+}
+
+func (m *memoryJobStore) Load() ([]QueuedJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return sortedJobs(m.jobs), nil
+}
+
+// compactMinRecords is how many log records a file store accumulates
+// before it considers compacting
+const compactMinRecords = 1000
+
+type fileJobStore struct {
+	mu        sync.Mutex
+	path      string
+	size      int64
+	records   int
+	jobs      map[string]QueuedJob
+	retention time.Duration
+	compacted time.Time
+	now       func() time.Time
+}
+
+// fileJobRecord is one line of the file store's log: a saved job or the ID
+// of a deleted one
+type fileJobRecord struct {
+	Job    *QueuedJob `json:"job,omitempty"`
+	Delete string     `json:"delete,omitempty"`
+}
+
+// FileJobStoreOption configures a store created by NewFileJobStore
+type FileJobStoreOption func(*fileJobStore)
+
+// WithDeadJobRetention drops dead jobs from the file once they have been
+// dead for longer than d. They stay in DeadLetters until the next restart.
+// By default dead jobs are kept.
+func WithDeadJobRetention(d time.Duration) FileJobStoreOption {
+	return func(f *fileJobStore) {
+		f.retention = d
+	}
+}
+
+// NewFileJobStore returns a store that keeps jobs in an append-only log at
+// path. Every change appends one JSON line and syncs the file. The log is
+// rewritten through a temporary file and a rename once it holds mostly
+// superseded records, or every retention period when WithDeadJobRetention
+// is set. A line left incomplete by a crash is discarded on open.
+func NewFileJobStore(path string, opts ...FileJobStoreOption) (JobStore, error) {
+	f := &fileJobStore{
+		path: path,
+		jobs: make(map[string]QueuedJob),
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if err := f.replay(); err != nil {
+		return nil, err
+	}
+	f.compacted = f.now()
+	return f, nil
+}
+
+func (f *fileJobStore) replay() error {
+	file, err := os.Open(f.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return fmt.Errorf("reading job store: %w", err)
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	for {
+		line, readErr := r.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return fmt.Errorf("reading job store: %w", readErr)
+		}
+		if readErr != nil {
+			// Every record ends in a newline, so a partial line is a torn
+			// append; the next write overwrites it
+			return nil
+		}
+
+		var rec fileJobRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("decoding job store %s: %w", f.path, err)
+		}
+		f.apply(rec)
+		f.size += int64(len(line))
+		f.records++
+	}
+}
+
+func (f *fileJobStore) apply(rec fileJobRecord) {
+	if rec.Job != nil {
+		f.jobs[rec.Job.ID] = *rec.Job
+	} else {
+		delete(f.jobs, rec.Delete)
+	}
+}
+
+func (f *fileJobStore) Save(job QueuedJob) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.write(fileJobRecord{Job: &job})
+}
+
+func (f *fileJobStore) Delete(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.jobs[id]; !ok {
+		return nil
+	}
+	return f.write(fileJobRecord{Delete: id})
+}
+
+func (f *fileJobStore) Load() ([]QueuedJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return sortedJobs(f.jobs), nil
+}
+
+// write appends rec to the log and applies it. Callers hold f.mu.
+func (f *fileJobStore) write(rec fileJobRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encoding job store: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := f.append(data); err != nil {
+		return fmt.Errorf("writing job store: %w", err)
+	}
+	f.apply(rec)
+	f.size += int64(len(data))
+	f.records++
+
+	if f.shouldCompact() {
+		// The record is durable, so a failed compaction only leaves a
+		// longer log
+		_ = f.compact()
+	}
+	return nil
+}
+
+// append writes data at the end of the valid log, cutting off anything a
+// failed or torn earlier write left behind
+func (f *fileJobStore) append(data []byte) error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := file.Truncate(f.size); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.WriteAt(data, f.size); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (f *fileJobStore) shouldCompact() bool {
+	if f.records >= compactMinRecords && f.records > 2*len(f.jobs) {
+		return true
+	}
+	return f.retention > 0 && f.now().Sub(f.compacted) >= f.retention
+}
+
+// compact rewrites the log with one record per live job, dropping dead
+// jobs past the retention period. Callers hold f.mu.
+func (f *fileJobStore) compact() error {
+	now := f.now()
+	f.compacted = now
+	if f.retention > 0 {
+		for id, job := range f.jobs {
+			if job.State == JobDead && now.Sub(job.UpdatedAt) > f.retention {
+				delete(f.jobs, id)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	jobs := sortedJobs(f.jobs)
+	for i := range jobs {
+		data, err := json.Marshal(fileJobRecord{Job: &jobs[i]})
+		if err != nil {
+			return fmt.Errorf("encoding job store: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("compacting job store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("compacting job store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("compacting job store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("compacting job store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("compacting job store: %w", err)
+	}
+	f.size = int64(buf.Len())
+	f.records = len(jobs)
+	return nil
+}
+
+func sortedJobs(jobs map[string]QueuedJob) []QueuedJob {
+	out := make([]QueuedJob, 0, len(jobs))
+	for _, job := range jobs {
+		out = append(out, job)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Seq < out[j].Seq })
+	return out
+}
@@ -0,0 +1,500 @@
+package pumped
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type jobPayload struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func awaitJob(t *testing.T, scope *Scope, id string, state JobState) QueuedJob {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := scope.Job(id)
+		if err != nil {
+			t.Fatalf("Job failed: %v", err)
+		}
+		if job.State == state {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	job, _ := scope.Job(id)
+	t.Fatalf("timed out waiting for job %s to be %s, last %+v", id, state, job)
+	return QueuedJob{}
+}
+
+func queueUnit() *Executor[struct{}] {
+	return Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+}
+
+func TestEnqueueRunsFlow(t *testing.T) {
+	scope := NewScope(WithJobQueue())
+	defer scope.Dispose()
+
+	got := make(chan jobPayload, 1)
+	flow := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		in, err := JobInput[jobPayload](execCtx)
+		if err != nil {
+			return 0, err
+		}
+		got <- in
+		return in.Count, nil
+	}, WithFlowTag(FlowName(), "greet"))
+
+	id, err := scope.Enqueue(flow, jobPayload{Name: "ada", Count: 2})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	job := awaitJob(t, scope, id, JobSucceeded)
+	if in := <-got; in.Name != "ada" {
+		t.Errorf("expected input ada, got %+v", in)
+	}
+	if job.Attempts != 1 || job.FlowName != "greet" {
+		t.Errorf("unexpected job record %+v", job)
+	}
+
+	node, ok := scope.JobExecution(id)
+	if !ok {
+		t.Fatal("expected execution node for job")
+	}
+	if jobID, _ := node.GetTag(queueJobIDTag); jobID != id {
+		t.Errorf("expected execution tagged with job ID %s, got %v", id, jobID)
+	}
+}
+
+func TestEnqueueErrors(t *testing.T) {
+	plain := NewScope()
+	defer plain.Dispose()
+
+	named := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 1, nil
+	}, WithFlowTag(FlowName(), "named"))
+	if _, err := plain.Enqueue(named, nil); !errors.Is(err, ErrNoJobQueue) {
+		t.Errorf("expected ErrNoJobQueue, got %v", err)
+	}
+
+	scope := NewScope(WithJobQueue())
+	unnamed := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 1, nil
+	})
+	if _, err := scope.Enqueue(unnamed, nil); err == nil {
+		t.Error("expected unnamed flow to be rejected")
+	}
+	if _, err := scope.Job("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+
+	scope.Dispose()
+	if _, err := scope.Enqueue(named, nil); !errors.Is(err, ErrQueueStopped) {
+		t.Errorf("expected ErrQueueStopped, got %v", err)
+	}
+}
+
+func TestQueuePriority(t *testing.T) {
+	scope := NewScope(WithJobQueue(WithQueueWorkers(1)))
+	defer scope.Dispose()
+
+	release := make(chan struct{})
+	blocker := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		<-release
+		return 0, nil
+	}, WithFlowTag(FlowName(), "blocker"))
+
+	var mu sync.Mutex
+	var order []string
+	record := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		name, _ := JobInput[string](execCtx)
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+		return 0, nil
+	}, WithFlowTag(FlowName(), "record"))
+
+	blockID, _ := scope.Enqueue(blocker, nil)
+	awaitJob(t, scope, blockID, JobRunning)
+
+	lowID, _ := scope.Enqueue(record, "low", WithPriority(1))
+	highID, _ := scope.Enqueue(record, "high", WithPriority(10))
+	close(release)
+
+	awaitJob(t, scope, lowID, JobSucceeded)
+	awaitJob(t, scope, highID, JobSucceeded)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 2 || order[0] != "high" || order[1] != "low" {
+		t.Errorf("expected high before low, got %v", order)
+	}
+}
+
+func TestQueueRunAt(t *testing.T) {
+	scope := NewScope(WithJobQueue(WithQueueWorkers(1)))
+	defer scope.Dispose()
+
+	var mu sync.Mutex
+	var order []string
+	record := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		name, _ := JobInput[string](execCtx)
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+		return 0, nil
+	}, WithFlowTag(FlowName(), "record"))
+
+	runAt := time.Now().Add(20 * time.Millisecond)
+	laterID, _ := scope.Enqueue(record, "later", WithPriority(10), WithRunAt(runAt))
+	nowID, _ := scope.Enqueue(record, "now")
+
+	awaitJob(t, scope, nowID, JobSucceeded)
+	job := awaitJob(t, scope, laterID, JobSucceeded)
+	if job.UpdatedAt.Before(runAt) {
+		t.Errorf("expected delayed job to run after %v, finished at %v", runAt, job.UpdatedAt)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 2 || order[0] != "now" || order[1] != "later" {
+		t.Errorf("expected due job before delayed one, got %v", order)
+	}
+}
+
+func TestQueueFlowConcurrencyLimit(t *testing.T) {
+	scope := NewScope(WithJobQueue(
+		WithQueueWorkers(4),
+		WithFlowConcurrency("limited", 1),
+	))
+	defer scope.Dispose()
+
+	var current, peak atomic.Int32
+	flow := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		n := current.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		current.Add(-1)
+		return 0, nil
+	}, WithFlowTag(FlowName(), "limited"))
+
+	var ids []string
+	for i := 0; i < 4; i++ {
+		id, err := scope.Enqueue(flow, i)
+		if err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	for _, id := range ids {
+		awaitJob(t, scope, id, JobSucceeded)
+	}
+
+	if peak.Load() != 1 {
+		t.Errorf("expected at most 1 concurrent run, got %d", peak.Load())
+	}
+}
+
+func TestQueueRetryAndDeadLetter(t *testing.T) {
+	dead := make(chan QueuedJob, 1)
+	scope := NewScope(WithJobQueue(
+		WithQueueRetry(RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     ExponentialBackoff(time.Millisecond, 4*time.Millisecond),
+		}),
+		WithDeadLetterHandler(func(job QueuedJob) { dead <- job }),
+	))
+	defer scope.Dispose()
+
+	var attempts atomic.Int32
+	flow := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		attempts.Add(1)
+		return 0, errors.New("unavailable")
+	}, WithFlowTag(FlowName(), "flaky"))
+
+	id, err := scope.Enqueue(flow, nil)
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	job := awaitJob(t, scope, id, JobDead)
+	if job.Attempts != 3 || attempts.Load() != 3 {
+		t.Errorf("expected 3 attempts, got record %d and runs %d", job.Attempts, attempts.Load())
+	}
+	if job.LastError != "unavailable" {
+		t.Errorf("expected last error recorded, got %q", job.LastError)
+	}
+
+	select {
+	case d := <-dead:
+		if d.ID != id {
+			t.Errorf("expected dead letter for %s, got %s", id, d.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dead letter handler was not called")
+	}
+
+	if letters := scope.DeadLetters(); len(letters) != 1 || letters[0].ID != id {
+		t.Errorf("expected job in dead letters, got %v", letters)
+	}
+
+	id, _ = scope.Enqueue(flow, nil, WithMaxAttempts(1))
+	if job := awaitJob(t, scope, id, JobDead); job.Attempts != 1 {
+		t.Errorf("expected per-job attempts override, got %d", job.Attempts)
+	}
+}
+
+func TestQueueDisposeWaitsForDeadLetterHandler(t *testing.T) {
+	var handled atomic.Bool
+	scope := NewScope(WithJobQueue(
+		WithDeadLetterHandler(func(job QueuedJob) {
+			time.Sleep(10 * time.Millisecond)
+			handled.Store(true)
+		}),
+	))
+
+	flow := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 0, errors.New("unavailable")
+	}, WithFlowTag(FlowName(), "failing"))
+
+	id, err := scope.Enqueue(flow, nil)
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	awaitJob(t, scope, id, JobDead)
+
+	scope.Dispose()
+	if !handled.Load() {
+		t.Error("expected Dispose to wait for the dead letter handler")
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	expected := []time.Duration{10, 20, 40, 50, 50}
+	for i, want := range expected {
+		if got := backoff(i + 1); got != want*time.Millisecond {
+			t.Errorf("attempt %d: expected %v, got %v", i+1, want*time.Millisecond, got)
+		}
+	}
+}
+
+func TestQueueSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+
+	got := make(chan jobPayload, 1)
+	flow := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		in, err := JobInput[jobPayload](execCtx)
+		if err != nil {
+			return 0, err
+		}
+		got <- in
+		return 0, nil
+	}, WithFlowTag(FlowName(), "persisted"))
+
+	store, err := NewFileJobStore(path)
+	if err != nil {
+		t.Fatalf("NewFileJobStore failed: %v", err)
+	}
+	first := NewScope(WithJobQueue(WithQueueWorkers(0), WithQueueStore(store)))
+	id, err := first.Enqueue(flow, jobPayload{Name: "grace", Count: 7}, WithPriority(3))
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	first.Dispose()
+
+	store, err = NewFileJobStore(path)
+	if err != nil {
+		t.Fatalf("reopening store failed: %v", err)
+	}
+	jobs, _ := store.Load()
+	if len(jobs) != 1 || jobs[0].ID != id || jobs[0].Priority != 3 || jobs[0].State != JobPending {
+		t.Fatalf("expected pending job persisted, got %+v", jobs)
+	}
+
+	second := NewScope(WithJobQueue(WithQueueStore(store), WithQueueFlows(flow)))
+	defer second.Dispose()
+
+	awaitJob(t, second, id, JobSucceeded)
+	if in := <-got; in.Name != "grace" || in.Count != 7 {
+		t.Errorf("expected decoded input, got %+v", in)
+	}
+
+	jobs, _ = store.Load()
+	if len(jobs) != 0 {
+		t.Errorf("expected succeeded job removed from store, got %d jobs", len(jobs))
+	}
+}
+
+type failingJobStore struct {
+	JobStore
+}
+
+func (failingJobStore) Load() ([]QueuedJob, error) {
+	return nil, errors.New("disk unavailable")
+}
+
+type cleanupErrorRecorder struct {
+	BaseExtension
+	mu   sync.Mutex
+	errs []*CleanupError
+}
+
+func (r *cleanupErrorRecorder) OnCleanupError(err *CleanupError) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+	return true
+}
+
+func TestFileJobStoreLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+
+	store, err := NewFileJobStore(path)
+	if err != nil {
+		t.Fatalf("NewFileJobStore failed: %v", err)
+	}
+	for i := 1; i <= compactMinRecords; i++ {
+		job := QueuedJob{ID: "a", State: JobPending, Attempts: i, Seq: 1}
+		if err := store.Save(job); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	store.Save(QueuedJob{ID: "b", State: JobPending, Seq: 2})
+	store.Delete("b")
+
+	if records := store.(*fileJobStore).records; records >= compactMinRecords {
+		t.Errorf("expected the log to be compacted, still %d records", records)
+	}
+
+	// Simulate a crash in the middle of an append
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	file.WriteString(`{"job":{"id":"c"`)
+	file.Close()
+
+	store, err = NewFileJobStore(path)
+	if err != nil {
+		t.Fatalf("reopening a torn log failed: %v", err)
+	}
+	if err := store.Save(QueuedJob{ID: "d", State: JobPending, Seq: 3}); err != nil {
+		t.Fatalf("Save after torn write failed: %v", err)
+	}
+
+	store, err = NewFileJobStore(path)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	jobs, _ := store.Load()
+	if len(jobs) != 2 || jobs[0].ID != "a" || jobs[0].Attempts != compactMinRecords || jobs[1].ID != "d" {
+		t.Errorf("unexpected jobs after replay: %+v", jobs)
+	}
+}
+
+func TestFileJobStoreDeadJobRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store, err := NewFileJobStore(path, WithDeadJobRetention(time.Hour))
+	if err != nil {
+		t.Fatalf("NewFileJobStore failed: %v", err)
+	}
+	fs := store.(*fileJobStore)
+	fs.now = func() time.Time { return now }
+	fs.compacted = now
+
+	store.Save(QueuedJob{ID: "old", State: JobDead, Seq: 1, UpdatedAt: now})
+	now = now.Add(30 * time.Minute)
+	store.Save(QueuedJob{ID: "recent", State: JobDead, Seq: 2, UpdatedAt: now})
+	now = now.Add(45 * time.Minute)
+	store.Save(QueuedJob{ID: "live", State: JobPending, Seq: 3, UpdatedAt: now})
+
+	store, err = NewFileJobStore(path)
+	if err != nil {
+		t.Fatalf("reopening failed: %v", err)
+	}
+	jobs, _ := store.Load()
+	if len(jobs) != 2 || jobs[0].ID != "recent" || jobs[1].ID != "live" {
+		t.Errorf("expected only the expired dead job dropped, got %+v", jobs)
+	}
+}
+
+func TestQueueStartFailure(t *testing.T) {
+	flow := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 0, nil
+	}, WithFlowTag(FlowName(), "never"))
+
+	recorder := &cleanupErrorRecorder{BaseExtension: NewBaseExtension("recorder")}
+	scope := NewScope(
+		WithExtension(recorder),
+		WithJobQueue(WithQueueStore(failingJobStore{NewMemoryJobStore()})),
+	)
+	defer scope.Dispose()
+
+	if _, err := scope.Enqueue(flow, nil); !errors.Is(err, ErrQueueUnavailable) {
+		t.Errorf("expected ErrQueueUnavailable, got %v", err)
+	}
+	if len(recorder.errs) != 1 || recorder.errs[0].Context != "queue" {
+		t.Errorf("expected the load failure reported to extensions, got %v", recorder.errs)
+	}
+
+	unnamed := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 0, nil
+	})
+	other := NewScope(WithJobQueue(WithQueueFlows(unnamed)))
+	defer other.Dispose()
+
+	if _, err := other.Enqueue(flow, nil); !errors.Is(err, ErrQueueUnavailable) {
+		t.Errorf("expected ErrQueueUnavailable for an unnamed flow, got %v", err)
+	}
+}
+
+func TestQueueForgetsFinishedJobs(t *testing.T) {
+	scope := NewScope(WithJobQueue(WithQueueWorkers(1), WithQueueHistory(2)))
+	defer scope.Dispose()
+
+	flow := Flow1(queueUnit(), func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 0, nil
+	}, WithFlowTag(FlowName(), "short"))
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id, err := scope.Enqueue(flow, i)
+		if err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		awaitJob(t, scope, id, JobSucceeded)
+		ids = append(ids, id)
+	}
+
+	if _, err := scope.Job(ids[0]); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("expected the oldest finished job to be forgotten, got %v", err)
+	}
+	if job, err := scope.Job(ids[4]); err != nil || job.State != JobSucceeded {
+		t.Errorf("expected the latest job in history, got %+v, %v", job, err)
+	}
+
+	q := scope.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.jobs) != 0 || len(q.finished) != 2 {
+		t.Errorf("expected no queued and 2 finished jobs, got %d and %d", len(q.jobs), len(q.finished))
+	}
+}
@@ -0,0 +1,423 @@
+package pumped
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrSchedulerStopped is returned when scheduling on a stopped scheduler
+var ErrSchedulerStopped = errors.New("scheduler stopped")
+
+// maxCatchUp bounds how many missed activations a job replays at once
+const maxCatchUp = 1000
+
+var (
+	scheduleNameTag  = NewTag[string]("schedule.name")
+	scheduledTimeTag = NewTag[time.Time]("schedule.time")
+)
+
+// ScheduleName is set on executions started by a Scheduler to the job name
+func ScheduleName() Tag[string] { return scheduleNameTag }
+
+// ScheduledTime is set on executions started by a Scheduler to the
+// activation time the run belongs to
+func ScheduledTime() Tag[time.Time] { return scheduledTimeTag }
+
+// Clock supplies time to a Scheduler. Tests inject their own implementation
+// to drive schedules without waiting.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SystemClock returns the wall clock
+func SystemClock() Clock { return systemClock{} }
+
+// OverlapPolicy decides what happens when a run is due while the previous
+// run of the same job is still executing
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the new run (default)
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs it after the current run finishes
+	OverlapQueue
+	// OverlapAllow runs it concurrently
+	OverlapAllow
+)
+
+// MissedRunPolicy decides what happens when several activations passed
+// before the scheduler could fire, for example after the process was
+// suspended or the clock jumped
+type MissedRunPolicy int
+
+const (
+	// MissedRunOnce fires a single run for all missed activations (default)
+	MissedRunOnce MissedRunPolicy = iota
+	// MissedRunAll fires one run per missed activation
+	MissedRunAll
+	// MissedRunSkip drops missed activations and waits for the next one
+	MissedRunSkip
+)
+
+// SchedulerOption configures a Scheduler
+type SchedulerOption func(*schedulerConfig)
+
+type schedulerConfig struct {
+	clock Clock
+}
+
+// WithSchedulerClock replaces the wall clock
+func WithSchedulerClock(clock Clock) SchedulerOption {
+	return func(cfg *schedulerConfig) {
+		cfg.clock = clock
+	}
+}
+
+// JobOption configures a scheduled job
+type JobOption func(*jobConfig)
+
+type jobConfig struct {
+	jitter   time.Duration
+	overlap  OverlapPolicy
+	missed   MissedRunPolicy
+	execOpts []ExecOption
+}
+
+// WithJitter delays each activation by a random duration in [0, d)
+func WithJitter(d time.Duration) JobOption {
+	return func(cfg *jobConfig) {
+		cfg.jitter = d
+	}
+}
+
+// WithOverlap sets the overlap policy
+func WithOverlap(policy OverlapPolicy) JobOption {
+	return func(cfg *jobConfig) {
+		cfg.overlap = policy
+	}
+}
+
+// WithMissedRuns sets the missed-run policy
+func WithMissedRuns(policy MissedRunPolicy) JobOption {
+	return func(cfg *jobConfig) {
+		cfg.missed = policy
+	}
+}
+
+// WithJobExecOptions passes options to every Exec the job performs
+func WithJobExecOptions(opts ...ExecOption) JobOption {
+	return func(cfg *jobConfig) {
+		cfg.execOpts = append(cfg.execOpts, opts...)
+	}
+}
+
+// Scheduler runs flows on intervals or cron schedules inside a scope. Runs
+// execute with Exec, so they appear in the ExecutionTree tagged with
+// ScheduleName and ScheduledTime.
+type Scheduler struct {
+	scope  *Scope
+	clock  Clock
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	jobs    map[string]*ScheduledJob
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewScheduler returns an executor for a Scheduler bound to the resolving
+// scope. The scheduler stops when the executor is cleaned up, so disposing
+// the scope stops every job and waits for in-flight runs.
+func NewScheduler(opts ...SchedulerOption) *Executor[*Scheduler] {
+	cfg := schedulerConfig{clock: SystemClock()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return Provide(func(ctx *ResolveCtx) (*Scheduler, error) {
+		s := newScheduler(ctx.scope, cfg)
+		ctx.OnCleanup(func() error {
+			s.Stop()
+			return nil
+		})
+		return s, nil
+	})
+}
+
+func newScheduler(scope *Scope, cfg schedulerConfig) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		scope:  scope,
+		clock:  cfg.clock,
+		ctx:    ctx,
+		cancel: cancel,
+		jobs:   make(map[string]*ScheduledJob),
+	}
+}
+
+// Stop cancels all jobs, cancels the context of in-flight runs and waits
+// for them to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.stopped = true
+	s.jobs = make(map[string]*ScheduledJob)
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Job returns the job registered under name
+func (s *Scheduler) Job(name string) (*ScheduledJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[name]
+	return job, ok
+}
+
+// ScheduleFlow registers flow to run on sched under a unique name
+func ScheduleFlow[R any](s *Scheduler, name string, flow *Flow[R], sched Schedule, opts ...JobOption) (*ScheduledJob, error) {
+	var cfg jobConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	job := &ScheduledJob{
+		name:      name,
+		sched:     sched,
+		cfg:       cfg,
+		scheduler: s,
+		stop:      make(chan struct{}),
+	}
+	job.run = func(ctx context.Context, at time.Time) error {
+		execOpts := append([]ExecOption{
+			WithExecTag(scheduleNameTag, name),
+			WithExecTag(scheduledTimeTag, at),
+		}, cfg.execOpts...)
+		_, _, err := Exec(s.scope, ctx, flow, execOpts...)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return nil, ErrSchedulerStopped
+	}
+	if _, exists := s.jobs[name]; exists {
+		return nil, fmt.Errorf("scheduler: job %q already exists", name)
+	}
+	s.jobs[name] = job
+
+	s.wg.Add(1)
+	go job.loop()
+
+	return job, nil
+}
+
+// JobStats counts what a scheduled job has done so far
+type JobStats struct {
+	Runs     uint64
+	Failures uint64
+	Skipped  uint64
+	Missed   uint64
+	Running  int
+	Queued   int
+}
+
+// ScheduledJob is a flow registered with a Scheduler
+type ScheduledJob struct {
+	name      string
+	sched     Schedule
+	cfg       jobConfig
+	scheduler *Scheduler
+	run       func(ctx context.Context, at time.Time) error
+
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mu      sync.Mutex
+	next    time.Time
+	queue   []time.Time
+	running int
+	stats   JobStats
+}
+
+// Name returns the job name
+func (j *ScheduledJob) Name() string {
+	return j.name
+}
+
+// Next returns the next activation, or the zero time once the schedule is
+// exhausted or the job is cancelled
+func (j *ScheduledJob) Next() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.next
+}
+
+// Stats returns a snapshot of the job counters
+func (j *ScheduledJob) Stats() JobStats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stats := j.stats
+	stats.Running = j.running
+	stats.Queued = len(j.queue)
+	return stats
+}
+
+// Cancel stops future activations and drops queued runs. A run already
+// executing finishes normally.
+func (j *ScheduledJob) Cancel() {
+	j.stopOnce.Do(func() {
+		close(j.stop)
+
+		s := j.scheduler
+		s.mu.Lock()
+		if s.jobs[j.name] == j {
+			delete(s.jobs, j.name)
+		}
+		s.mu.Unlock()
+	})
+}
+
+func (j *ScheduledJob) setNext(t time.Time) {
+	j.mu.Lock()
+	j.next = t
+	j.mu.Unlock()
+}
+
+func (j *ScheduledJob) cancelled() bool {
+	select {
+	case <-j.stop:
+		return true
+	case <-j.scheduler.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func (j *ScheduledJob) loop() {
+	s := j.scheduler
+	defer s.wg.Done()
+	defer j.setNext(time.Time{})
+
+	next := j.sched.Next(s.clock.Now())
+	for !next.IsZero() {
+		j.setNext(next)
+
+		wait := next.Sub(s.clock.Now())
+		if j.cfg.jitter > 0 {
+			wait += time.Duration(rand.Int64N(int64(j.cfg.jitter)))
+		}
+		if wait < 0 {
+			wait = 0
+		}
+
+		select {
+		case <-s.clock.After(wait):
+		case <-j.stop:
+			return
+		case <-s.ctx.Done():
+			return
+		}
+
+		now := s.clock.Now()
+		due := []time.Time{next}
+		following := j.sched.Next(next)
+		for !following.IsZero() && !following.After(now) {
+			if len(due) == maxCatchUp {
+				following = j.sched.Next(now)
+				break
+			}
+			due = append(due, following)
+			following = j.sched.Next(following)
+		}
+
+		switch j.cfg.missed {
+		case MissedRunAll:
+			for _, at := range due {
+				j.fire(at)
+			}
+		case MissedRunSkip:
+			if len(due) == 1 {
+				j.fire(due[0])
+			} else {
+				j.addMissed(len(due))
+			}
+		default:
+			j.fire(due[len(due)-1])
+			j.addMissed(len(due) - 1)
+		}
+
+		next = following
+	}
+}
+
+func (j *ScheduledJob) addMissed(n int) {
+	j.mu.Lock()
+	j.stats.Missed += uint64(n)
+	j.mu.Unlock()
+}
+
+// fire starts a run for activation at, honoring the overlap policy. It is
+// only called from the job loop, which keeps the scheduler's WaitGroup
+// above zero while runs are added.
+func (j *ScheduledJob) fire(at time.Time) {
+	j.mu.Lock()
+	switch {
+	case j.running == 0 || j.cfg.overlap == OverlapAllow:
+		j.running++
+	case j.cfg.overlap == OverlapQueue:
+		j.queue = append(j.queue, at)
+		j.mu.Unlock()
+		return
+	default:
+		j.stats.Skipped++
+		j.mu.Unlock()
+		return
+	}
+	j.mu.Unlock()
+
+	s := j.scheduler
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			err := j.run(s.ctx, at)
+
+			j.mu.Lock()
+			j.stats.Runs++
+			if err != nil {
+				j.stats.Failures++
+			}
+			if len(j.queue) == 0 || j.cancelled() {
+				j.queue = nil
+				j.running--
+				j.mu.Unlock()
+				return
+			}
+			at = j.queue[0]
+			j.queue = j.queue[1:]
+			j.mu.Unlock()
+		}
+	}()
+}
@@ -0,0 +1,363 @@
+package pumped
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	waits   []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if !w.at.After(c.now) {
+			w.ch <- c.now
+		} else {
+			pending = append(pending, w)
+		}
+	}
+	c.waiters = pending
+}
+
+// awaitWaiters blocks until n goroutines are waiting on the clock
+func (c *fakeClock) awaitWaiters(t *testing.T, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		count := len(c.waiters)
+		c.mu.Unlock()
+		if count >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d clock waiters", n)
+}
+
+func awaitStats(t *testing.T, job *ScheduledJob, cond func(JobStats) bool) JobStats {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if stats := job.Stats(); cond(stats) {
+			return stats
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for job stats, last %+v", job.Stats())
+	return JobStats{}
+}
+
+func newTestScheduler(t *testing.T, clock Clock) (*Scope, *Scheduler) {
+	t.Helper()
+
+	scope := NewScope()
+	sched, err := Resolve(scope, NewScheduler(WithSchedulerClock(clock)))
+	if err != nil {
+		t.Fatalf("Resolve scheduler failed: %v", err)
+	}
+	return scope, sched
+}
+
+func TestSchedulerRunsOnInterval(t *testing.T) {
+	clock := newFakeClock()
+	scope, sched := newTestScheduler(t, clock)
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 1, nil
+	}, WithFlowTag(FlowName(), "tick"))
+
+	job, err := ScheduleFlow(sched, "ticker", flow, Every(time.Minute))
+	if err != nil {
+		t.Fatalf("ScheduleFlow failed: %v", err)
+	}
+
+	start := clock.Now()
+
+	for i := 1; i <= 3; i++ {
+		clock.awaitWaiters(t, 1)
+		clock.Advance(time.Minute)
+		awaitStats(t, job, func(s JobStats) bool { return s.Runs == uint64(i) })
+	}
+
+	nodes := scope.GetExecutionTree().Filter(func(node *ExecutionNode) bool {
+		name, _ := node.GetTag(scheduleNameTag)
+		return name == "ticker"
+	})
+	if len(nodes) != 3 {
+		t.Fatalf("expected 3 recorded executions, got %d", len(nodes))
+	}
+	seen := make(map[time.Time]bool)
+	for _, node := range nodes {
+		at, _ := node.GetTag(scheduledTimeTag)
+		seen[at.(time.Time)] = true
+	}
+	for i := 1; i <= 3; i++ {
+		if want := start.Add(time.Duration(i) * time.Minute); !seen[want] {
+			t.Errorf("expected an execution scheduled at %v", want)
+		}
+	}
+
+	if _, err := ScheduleFlow(sched, "ticker", flow, Every(time.Minute)); err == nil {
+		t.Error("expected duplicate job name to fail")
+	}
+}
+
+func blockingFlow(started chan<- struct{}, release <-chan struct{}) *Flow[int] {
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	return Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		started <- struct{}{}
+		select {
+		case <-release:
+			return 1, nil
+		case <-execCtx.Context().Done():
+			return 0, execCtx.Context().Err()
+		}
+	})
+}
+
+func TestSchedulerOverlapPolicies(t *testing.T) {
+	tests := []struct {
+		policy  OverlapPolicy
+		runs    uint64
+		skipped uint64
+	}{
+		{OverlapSkip, 1, 1},
+		{OverlapQueue, 2, 0},
+		{OverlapAllow, 2, 0},
+	}
+
+	for _, tt := range tests {
+		clock := newFakeClock()
+		scope, sched := newTestScheduler(t, clock)
+
+		started := make(chan struct{}, 4)
+		release := make(chan struct{})
+		job, err := ScheduleFlow(sched, "job", blockingFlow(started, release), Every(time.Minute), WithOverlap(tt.policy))
+		if err != nil {
+			t.Fatalf("ScheduleFlow failed: %v", err)
+		}
+
+		clock.awaitWaiters(t, 1)
+		clock.Advance(time.Minute)
+		<-started
+
+		clock.awaitWaiters(t, 1)
+		clock.Advance(time.Minute)
+
+		switch tt.policy {
+		case OverlapSkip:
+			awaitStats(t, job, func(s JobStats) bool { return s.Skipped == 1 })
+		case OverlapQueue:
+			stats := awaitStats(t, job, func(s JobStats) bool { return s.Queued == 1 })
+			if stats.Running != 1 {
+				t.Errorf("queue: expected 1 running, got %d", stats.Running)
+			}
+		case OverlapAllow:
+			<-started
+		}
+
+		close(release)
+		if tt.policy == OverlapQueue {
+			<-started
+		}
+
+		stats := awaitStats(t, job, func(s JobStats) bool { return s.Runs == tt.runs && s.Running == 0 })
+		if stats.Skipped != tt.skipped {
+			t.Errorf("policy %d: expected %d skipped, got %d", tt.policy, tt.skipped, stats.Skipped)
+		}
+
+		scope.Dispose()
+	}
+}
+
+func TestSchedulerMissedRuns(t *testing.T) {
+	tests := []struct {
+		policy MissedRunPolicy
+		runs   uint64
+		missed uint64
+	}{
+		{MissedRunOnce, 1, 4},
+		{MissedRunAll, 5, 0},
+		{MissedRunSkip, 0, 5},
+	}
+
+	for _, tt := range tests {
+		clock := newFakeClock()
+		scope, sched := newTestScheduler(t, clock)
+
+		unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+			return struct{}{}, nil
+		})
+		flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+			return 1, nil
+		})
+
+		job, err := ScheduleFlow(sched, "job", flow, Every(time.Minute),
+			WithMissedRuns(tt.policy), WithOverlap(OverlapQueue))
+		if err != nil {
+			t.Fatalf("ScheduleFlow failed: %v", err)
+		}
+
+		clock.awaitWaiters(t, 1)
+		clock.Advance(5 * time.Minute)
+		clock.awaitWaiters(t, 1)
+
+		stats := awaitStats(t, job, func(s JobStats) bool { return s.Runs == tt.runs && s.Running == 0 })
+		if stats.Missed != tt.missed {
+			t.Errorf("policy %d: expected %d missed, got %d", tt.policy, tt.missed, stats.Missed)
+		}
+		if want := clock.Now().Add(time.Minute); !job.Next().Equal(want) {
+			t.Errorf("policy %d: expected next activation %v, got %v", tt.policy, want, job.Next())
+		}
+
+		scope.Dispose()
+	}
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	clock := newFakeClock()
+	scope, sched := newTestScheduler(t, clock)
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 1, nil
+	})
+
+	job, err := ScheduleFlow(sched, "job", flow, Every(time.Minute), WithJitter(10*time.Second))
+	if err != nil {
+		t.Fatalf("ScheduleFlow failed: %v", err)
+	}
+
+	clock.awaitWaiters(t, 1)
+	clock.Advance(time.Minute + 10*time.Second)
+	awaitStats(t, job, func(s JobStats) bool { return s.Runs == 1 })
+
+	clock.mu.Lock()
+	wait := clock.waits[0]
+	clock.mu.Unlock()
+	if wait < time.Minute || wait >= time.Minute+10*time.Second {
+		t.Errorf("expected wait within jitter window, got %v", wait)
+	}
+}
+
+func TestSchedulerStopsOnDispose(t *testing.T) {
+	clock := newFakeClock()
+	scope, sched := newTestScheduler(t, clock)
+
+	started := make(chan struct{}, 1)
+	job, err := ScheduleFlow(sched, "job", blockingFlow(started, nil), Every(time.Minute))
+	if err != nil {
+		t.Fatalf("ScheduleFlow failed: %v", err)
+	}
+
+	clock.awaitWaiters(t, 1)
+	clock.Advance(time.Minute)
+	<-started
+
+	if err := scope.Dispose(); err != nil {
+		t.Fatalf("Dispose failed: %v", err)
+	}
+
+	stats := job.Stats()
+	if stats.Running != 0 || stats.Failures != 1 {
+		t.Errorf("expected in-flight run cancelled, got %+v", stats)
+	}
+	if !job.Next().IsZero() {
+		t.Errorf("expected no next activation after stop")
+	}
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 1, nil
+	})
+	if _, err := ScheduleFlow(sched, "late", flow, Every(time.Minute)); !errors.Is(err, ErrSchedulerStopped) {
+		t.Errorf("expected ErrSchedulerStopped, got %v", err)
+	}
+}
+
+func TestScheduledJobCancel(t *testing.T) {
+	clock := newFakeClock()
+	scope, sched := newTestScheduler(t, clock)
+	defer scope.Dispose()
+
+	unit := Provide(func(ctx *ResolveCtx) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	flow := Flow1(unit, func(execCtx *ExecutionCtx, _ *Controller[struct{}]) (int, error) {
+		return 1, nil
+	})
+
+	job, err := ScheduleFlow(sched, "job", flow, MustParseCron("*/5 * * * *"))
+	if err != nil {
+		t.Fatalf("ScheduleFlow failed: %v", err)
+	}
+	if _, ok := sched.Job("job"); !ok {
+		t.Fatal("expected job to be registered")
+	}
+
+	clock.awaitWaiters(t, 1)
+	job.Cancel()
+	if _, ok := sched.Job("job"); ok {
+		t.Error("expected job to be removed")
+	}
+
+	clock.Advance(10 * time.Minute)
+	time.Sleep(10 * time.Millisecond)
+	if runs := job.Stats().Runs; runs != 0 {
+		t.Errorf("expected no runs after cancel, got %d", runs)
+	}
+}
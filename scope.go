@@ -18,10 +18,29 @@ type Scope struct {
 	graph           *ReactiveGraph
 	extensions      []Extension
 	presets         map[AnyExecutor]preset
+	decorators      map[AnyExecutor][]decorator
 	cleanupRegistry map[AnyExecutor][]cleanupEntry
 	cleanupMu       sync.RWMutex
 	execTree        *ExecutionTree
 	idCounter       atomic.Uint64
+	gracePeriod     time.Duration
+	stackTraces     bool
+	flowsInFlight   atomic.Int64
+	flowsAbandoned  atomic.Int64
+	events          eventBus
+	queue           *jobQueue
+
+	// failures holds errors of Optional dependencies until invalidation
+	failures sync.Map
+
+	// shared holds extensions a fork inherited without its own instance;
+	// the fork does not dispose them
+	shared map[Extension]bool
+
+	// parent is set on per-execution overlay scopes created for Exec presets
+	parent       *Scope
+	overrideMu   sync.Mutex
+	overrideMemo map[AnyExecutor]bool
 }
 
 type preset struct {
@@ -52,20 +71,44 @@ func WithExtension(ext Extension) ScopeOption {
 // WithPreset returns an option that sets a preset for an executor
 func WithPreset[T any](original *Executor[T], replacement any) ScopeOption {
 	return func(s *Scope) {
-		switch r := replacement.(type) {
-		case T:
-			s.presets[original] = preset{
-				value:   r,
-				isValue: true,
-			}
-		case *Executor[T]:
-			s.presets[original] = preset{
-				executor: r,
-				isValue:  false,
-			}
-		default:
-			panic(fmt.Sprintf("preset must be value of type %T or *Executor[%T]", *new(T), *new(T)))
+		p, err := newPreset[T](replacement)
+		if err != nil {
+			panic(err.Error())
 		}
+		s.presets[original] = p
+	}
+}
+
+func newPreset[T any](replacement any) (preset, error) {
+	switch r := replacement.(type) {
+	case T:
+		return preset{
+			value:   r,
+			isValue: true,
+		}, nil
+	case *Executor[T]:
+		return preset{
+			executor: r,
+			isValue:  false,
+		}, nil
+	default:
+		return preset{}, fmt.Errorf("preset must be value of type %T or *Executor[%T]", *new(T), *new(T))
+	}
+}
+
+// WithCancellationGracePeriod returns an option that sets how long a cancelled
+// flow waits for its factory to return before abandoning it
+func WithCancellationGracePeriod(d time.Duration) ScopeOption {
+	return func(s *Scope) {
+		s.gracePeriod = d
+	}
+}
+
+// WithStackTraces returns an option that captures a stack trace for every
+// ResolveError. Capturing is costly, so by default only panics carry one.
+func WithStackTraces() ScopeOption {
+	return func(s *Scope) {
+		s.stackTraces = true
 	}
 }
 
@@ -83,6 +126,10 @@ func NewScope(opts ...ScopeOption) *Scope {
 		opt(s)
 	}
 
+	if s.queue != nil {
+		s.queue.start()
+	}
+
 	return s
 }
 
@@ -94,14 +141,26 @@ func Accessor[T any](s *Scope, exec *Executor[T]) *Controller[T] {
 	}
 }
 
-// Resolve resolves an executor's value (lazily, with caching)
+// Resolve resolves an executor's value according to its lifetime: singletons
+// are cached by the scope, scoped executors by this scope only, and transient
+// executors are built on every call with cleanups owned by the scope
 func Resolve[T any](s *Scope, exec *Executor[T]) (T, error) {
+	return resolveFor(s, exec, nil)
+}
+
+// resolveCached resolves exec through the scope cache. Overlays delegate to
+// their parent when shared is set and exec is not overridden.
+func resolveCached[T any](s *Scope, exec *Executor[T], shared bool) (T, error) {
 	var zero T
 
+	if shared && s.parent != nil && !s.overrides(exec) {
+		return resolveCached(s.parent, exec, true)
+	}
+
 	if val, ok := s.cache.Load(exec); ok {
 		typedVal, err := SafeTypeAssertion[T](val)
 		if err != nil {
-			return zero, CreateResolveError(exec, err, "cache_retrieval")
+			return zero, s.resolveError(exec, err, "cache_retrieval")
 		}
 		return typedVal, nil
 	}
@@ -117,104 +176,100 @@ func Resolve[T any](s *Scope, exec *Executor[T]) (T, error) {
 	s.mu.Unlock()
 
 	// Check for preset
+	preset, hasPreset := s.lookupPreset(exec)
 	s.mu.RLock()
-	preset, hasPreset := s.presets[exec]
 	exts := s.extensions
 	s.mu.RUnlock()
 
 	if hasPreset {
 		if preset.isValue {
 			// Value preset - cache and return
-			typedVal, err := SafeTypeAssertion[T](preset.value)
+			val, err := s.decorate(exec, preset.value, nil)
+			if err != nil {
+				return zero, err
+			}
+			typedVal, err := SafeTypeAssertion[T](val)
 			if err != nil {
 				var zero T
-				return zero, CreateResolveError(exec, err, "preset_value_type_assertion")
+				return zero, s.resolveError(exec, err, "preset_value_type_assertion")
 			}
-			s.cache.Store(exec, preset.value)
+			s.cache.Store(exec, val)
+			s.publishExecutor(EventExecutorResolved, exec)
 			return typedVal, nil
 		}
 
 		// Executor preset - resolve replacement
 		val, err := preset.executor.ResolveAny(s)
 		if err != nil {
-			var zero T
+			return zero, s.resolveError(exec, err, "preset")
+		}
+		val, err = s.decorate(exec, val, nil)
+		if err != nil {
 			return zero, err
 		}
 
 		typedVal, typeErr := SafeTypeAssertion[T](val)
 		if typeErr != nil {
 			var zero T
-			return zero, CreateResolveError(exec, typeErr, "preset_executor_type_assertion")
+			return zero, s.resolveError(exec, typeErr, "preset_executor_type_assertion")
 		}
 
 		s.cache.Store(exec, val)
+		s.publishExecutor(EventExecutorResolved, exec)
 		return typedVal, nil
 	}
 
-	// Resolve dependencies first (skip lazy dependencies)
+	if err := validateLifetime(exec); err != nil {
+		return zero, s.resolveError(exec, err, "lifetime_validation")
+	}
+
+	// Resolve dependencies first (skip lazy dependencies, and shorter-lived
+	// ones which resolve on Get)
 	for _, dep := range exec.deps {
-		if dep.GetMode() == ModeLazy {
+		if dep.GetMode() == ModeLazy || LifetimeOf(dep.GetExecutor()) != Singleton {
 			continue
 		}
-		_, err := dep.GetExecutor().ResolveAny(s)
-		if err != nil {
-			var zero T
-			return zero, err
+		_, err := resolveDeclared(s, dep)
+		if err != nil && dep.GetMode() != ModeOptional {
+			return zero, s.resolveError(exec, err, "dependency")
 		}
 	}
 
-	// Wrap resolution with extensions
-	op := &Operation{
-		Kind:     OpResolve,
-		Executor: exec,
-		Scope:    s,
-	}
-
-	var result any
-	var err error
-
-	// Use context.Background() for now since Resolve doesn't take context parameter
-	// Extensions can still provide context-aware behavior if needed
-	ctx := context.Background()
-
-	// Chain extensions (middleware pattern)
-	next := func() (any, error) {
+	result, err := wrapResolve(s, exts, exec, func() (any, error) {
 		return exec.ResolveAny(s)
+	})
+	if err != nil {
+		return zero, s.resolveError(exec, err, "factory")
 	}
-
-	// Apply extensions in reverse order (last registered wraps first)
-	for i := len(exts) - 1; i >= 0; i-- {
-		ext := exts[i]
-		currentNext := next
-		next = func() (any, error) {
-			return ext.Wrap(ctx, currentNext, op)
-		}
-	}
-
-	result, err = next()
-
+	result, err = s.decorate(exec, result, nil)
 	if err != nil {
-		// Notify extensions of error
-		for _, ext := range exts {
-			ext.OnError(err, op, s)
-		}
-		var zero T
 		return zero, err
 	}
 
 	s.cache.Store(exec, result)
+	s.publishExecutor(EventExecutorResolved, exec)
 
 	typedResult, err := SafeTypeAssertion[T](result)
 	if err != nil {
 		var zero T
-		return zero, CreateResolveError(exec, err, "resolution_type_assertion")
+		return zero, s.resolveError(exec, err, "resolution_type_assertion")
 	}
 
 	return typedResult, nil
 }
 
-// Update changes an executor's cached value and propagates to reactive dependents
+// Update changes an executor's cached value and propagates to reactive
+// dependents. The value passes through the executor's decorators first; a
+// decorator error fails the update and keeps the current value.
 func Update[T any](ctx context.Context, s *Scope, exec *Executor[T], newVal T) error {
+	if l := LifetimeOf(exec); l == Transient {
+		return s.resolveError(exec, fmt.Errorf("%s executors have no cached value to update", l), "update")
+	}
+
+	if owner := s.owner(exec); owner != s {
+		return Update(ctx, owner, exec, newVal)
+	}
+
 	// Wrap update with extensions
 	s.mu.RLock()
 	exts := s.extensions
@@ -232,12 +287,21 @@ func Update[T any](ctx context.Context, s *Scope, exec *Executor[T], newVal T) e
 			return nil, err
 		}
 
+		// Decorate before touching the cache so a failing decorator leaves
+		// the current value in place
+		decCtx := &ResolveCtx{scope: s, executorID: exec}
+		decorated, err := s.decorate(exec, newVal, decCtx)
+		if err != nil {
+			return nil, err
+		}
+
 		s.mu.Lock()
 		toInvalidate := s.findReactiveDependents(exec)
 		s.mu.Unlock()
 
 		// Clean up the executor being updated (always complete this)
 		s.cleanupExecutor(exec)
+		s.registerCleanups(exec, decCtx.takeCleanups())
 
 		// Gracefully cleanup dependents - check context between each
 		completedCleanups := 0
@@ -257,10 +321,14 @@ func Update[T any](ctx context.Context, s *Scope, exec *Executor[T], newVal T) e
 				completedCleanups, len(toInvalidate), err)
 		}
 
-		s.cache.Store(exec, newVal)
+		s.cache.Store(exec, decorated)
+		s.failures.Delete(exec)
+		s.publishExecutor(EventExecutorUpdated, exec)
 
 		for _, dependent := range toInvalidate {
 			s.cache.Delete(dependent)
+			s.failures.Delete(dependent)
+			s.publishExecutor(EventExecutorInvalidated, dependent)
 		}
 		return nil, nil
 	}
@@ -278,6 +346,42 @@ func Update[T any](ctx context.Context, s *Scope, exec *Executor[T], newVal T) e
 	return err
 }
 
+// wrapResolve runs a resolution through the extension chain and notifies
+// extensions of failures
+func wrapResolve(s *Scope, exts []Extension, exec AnyExecutor, resolve func() (any, error)) (any, error) {
+	op := &Operation{
+		Kind:     OpResolve,
+		Executor: exec,
+		Scope:    s,
+	}
+
+	// Use context.Background() for now since Resolve doesn't take context parameter
+	// Extensions can still provide context-aware behavior if needed
+	ctx := context.Background()
+
+	// Chain extensions (middleware pattern)
+	next := resolve
+
+	// Apply extensions in reverse order (last registered wraps first)
+	for i := len(exts) - 1; i >= 0; i-- {
+		ext := exts[i]
+		currentNext := next
+		next = func() (any, error) {
+			return ext.Wrap(ctx, currentNext, op)
+		}
+	}
+
+	result, err := next()
+	if err != nil {
+		// Notify extensions of error
+		for _, ext := range exts {
+			ext.OnError(err, op, s)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
 // findReactiveDependents walks the dependency graph to find all reactive dependents
 func (s *Scope) findReactiveDependents(exec AnyExecutor) []AnyExecutor {
 	// Use new reactive graph for safe, iterative traversal
@@ -306,6 +410,18 @@ func (s *Scope) registerCleanups(exec AnyExecutor, entries []cleanupEntry) {
 	s.cleanupRegistry[exec] = entries
 }
 
+// appendCleanups adds cleanups to those already registered for exec, for
+// cleanups registered after its factory returned
+func (s *Scope) appendCleanups(exec AnyExecutor, entries []cleanupEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	s.cleanupMu.Lock()
+	defer s.cleanupMu.Unlock()
+	s.cleanupRegistry[exec] = append(s.cleanupRegistry[exec], entries...)
+}
+
 func (s *Scope) cleanupExecutor(exec AnyExecutor) {
 	s.cleanupMu.Lock()
 	entries := s.cleanupRegistry[exec]
@@ -320,38 +436,46 @@ func (s *Scope) cleanupExecutor(exec AnyExecutor) {
 }
 
 func (s *Scope) runCleanups(entries []cleanupEntry, exec AnyExecutor, cleanupContext string) {
-	s.mu.RLock()
-	exts := make([]Extension, len(s.extensions))
-	copy(exts, s.extensions)
-	s.mu.RUnlock()
-
 	for i := len(entries) - 1; i >= 0; i-- {
 		entry := entries[i]
 
 		if err := entry.fn(); err != nil {
-			cleanupErr := &CleanupError{
+			s.handleCleanupError(&CleanupError{
 				ExecutorID: exec,
 				Err:        err,
 				Context:    cleanupContext,
-			}
+			})
+		}
+	}
 
-			handled := false
-			for _, ext := range exts {
-				if ext.OnCleanupError(cleanupErr) {
-					handled = true
-					break
-				}
-			}
-			//nolint:staticcheck
-			if !handled {
-				// Future: could log or handle unhandled cleanup errors
-			}
+	s.publishExecutor(EventExecutorCleanedUp, exec)
+}
+
+func (s *Scope) handleCleanupError(cleanupErr *CleanupError) {
+	s.mu.RLock()
+	exts := make([]Extension, len(s.extensions))
+	copy(exts, s.extensions)
+	s.mu.RUnlock()
+
+	handled := false
+	for _, ext := range exts {
+		if ext.OnCleanupError(cleanupErr) {
+			handled = true
+			break
 		}
 	}
+	//nolint:staticcheck
+	if !handled {
+		// Future: could log or handle unhandled cleanup errors
+	}
 }
 
 // Dispose cleans up the scope and all its extensions
 func (s *Scope) Dispose() error {
+	if s.queue != nil {
+		s.queue.stop()
+	}
+
 	s.cleanupMu.Lock()
 	allEntries := make([]struct {
 		exec    AnyExecutor
@@ -376,6 +500,9 @@ func (s *Scope) Dispose() error {
 	s.mu.RUnlock()
 
 	for _, ext := range exts {
+		if s.shared[ext] {
+			continue
+		}
 		if err := ext.Dispose(s); err != nil {
 			return fmt.Errorf("disposing extension %s: %w", ext.Name(), err)
 		}
@@ -386,7 +513,10 @@ func (s *Scope) Dispose() error {
 
 // GetTag retrieves a tag value from the scope
 func (s *Scope) GetTag(tag any) (any, bool) {
-	return s.tags.Load(tag)
+	if val, ok := s.tags.Load(tag); ok || s.parent == nil {
+		return val, ok
+	}
+	return s.parent.GetTag(tag)
 }
 
 // SetTag stores a tag value on the scope
@@ -404,23 +534,85 @@ func (s *Scope) ExportDependencyGraph() map[AnyExecutor][]AnyExecutor {
 	return s.graph.ExportAllDependencies()
 }
 
+// FlowStats reports flow factory goroutines that have not returned yet
+type FlowStats struct {
+	// InFlight counts factories currently running, abandoned or not
+	InFlight int64
+	// Abandoned counts factories still running after their caller returned
+	Abandoned int64
+}
+
+// FlowStats returns the number of running and abandoned flow factories,
+// useful for detecting leaked goroutines in tests
+func (s *Scope) FlowStats() FlowStats {
+	root := s.root()
+	return FlowStats{
+		InFlight:  root.flowsInFlight.Load(),
+		Abandoned: root.flowsAbandoned.Load(),
+	}
+}
+
 func (s *Scope) generateExecutionID() string {
+	if s.parent != nil {
+		return s.parent.generateExecutionID()
+	}
 	return fmt.Sprintf("exec-%d", s.idCounter.Add(1))
 }
 
-func Exec[R any](s *Scope, ctx context.Context, flow *Flow[R]) (R, *ExecutionCtx, error) {
+// ExecOption configures a single root flow execution
+type ExecOption func(*execConfig)
+
+type execConfig struct {
+	presets map[AnyExecutor]preset
+	tags    map[any]any
+	err     error
+}
+
+// WithExecTag returns an option that sets a tag on the root execution
+// context before extensions see it
+func WithExecTag[T any](tag Tag[T], value T) ExecOption {
+	return func(cfg *execConfig) {
+		if cfg.tags == nil {
+			cfg.tags = make(map[any]any)
+		}
+		cfg.tags[tag] = value
+	}
+}
+
+// WithExecPreset returns an option that replaces an executor for one
+// execution tree only. Like WithPreset, the replacement is either a value of
+// type T or an *Executor[T]. Values resolved because of the override are
+// cached per execution and never reach the shared scope cache; sub-flows
+// started with Exec1 see the same overrides.
+func WithExecPreset[T any](original *Executor[T], replacement any) ExecOption {
+	return func(cfg *execConfig) {
+		p, err := newPreset[T](replacement)
+		if err != nil {
+			cfg.err = errors.Join(cfg.err, err)
+			return
+		}
+		if cfg.presets == nil {
+			cfg.presets = make(map[AnyExecutor]preset)
+		}
+		cfg.presets[original] = p
+	}
+}
+
+func Exec[R any](s *Scope, ctx context.Context, flow *Flow[R], opts ...ExecOption) (R, *ExecutionCtx, error) {
 	var zero R
 
+	cfg := &execConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.err != nil {
+		return zero, nil, cfg.err
+	}
+
 	// Check for cancellation before resolving dependencies
 	select {
 	case <-ctx.Done():
-		execCtx := &ExecutionCtx{
-			id:     s.generateExecutionID(),
-			parent: nil,
-			scope:  s,
-			data:   make(map[any]any),
-			ctx:    ctx,
-		}
+		execCtx := newExecutionCtx(s, nil, ctx)
 		execCtx.Set(endTimeTag, time.Now())
 		execCtx.Set(statusTag, ExecutionStatusCancelled)
 		execCtx.Set(errorTag, ctx.Err())
@@ -428,43 +620,41 @@ func Exec[R any](s *Scope, ctx context.Context, flow *Flow[R]) (R, *ExecutionCtx
 	default:
 	}
 
+	if len(cfg.presets) > 0 {
+		s = newOverlayScope(s, cfg.presets)
+	}
+
 	for _, dep := range flow.deps {
-		if dep.GetMode() == ModeLazy {
+		if dep.GetMode() == ModeLazy || LifetimeOf(dep.GetExecutor()) != Singleton {
 			continue
 		}
 		// Check for cancellation before each dependency resolution
 		select {
 		case <-ctx.Done():
-			execCtx := &ExecutionCtx{
-				id:     s.generateExecutionID(),
-				parent: nil,
-				scope:  s,
-				data:   make(map[any]any),
-				ctx:    ctx,
-			}
+			s.releaseOverlay()
+			execCtx := newExecutionCtx(s, nil, ctx)
 			execCtx.Set(endTimeTag, time.Now())
 			execCtx.Set(statusTag, ExecutionStatusCancelled)
 			execCtx.Set(errorTag, ctx.Err())
 			return zero, execCtx, ctx.Err()
 		default:
 		}
-		_, err := dep.GetExecutor().ResolveAny(s)
-		if err != nil {
+		_, err := resolveDeclared(s, dep)
+		if err != nil && dep.GetMode() != ModeOptional {
+			s.releaseOverlay()
 			return zero, nil, fmt.Errorf("resolving dependency: %w", err)
 		}
 	}
 
-	execCtx := &ExecutionCtx{
-		id:     s.generateExecutionID(),
-		parent: nil,
-		scope:  s,
-		data:   make(map[any]any),
-		ctx:    ctx,
-	}
+	execCtx := newExecutionCtx(s, nil, ctx)
+	execCtx.whenDone(s.releaseOverlay)
 
 	if name, ok := flow.GetTag(flowNameTag); ok {
 		execCtx.Set(flowNameTag, name)
 	}
+	for tag, value := range cfg.tags {
+		execCtx.Set(tag, value)
+	}
 
 	execCtx.Set(startTimeTag, time.Now())
 	execCtx.Set(statusTag, ExecutionStatusRunning)
@@ -478,30 +668,35 @@ func Exec[R any](s *Scope, ctx context.Context, flow *Flow[R]) (R, *ExecutionCtx
 		if err := ext.OnFlowStart(execCtx, flow); err != nil {
 			execCtx.Set(statusTag, ExecutionStatusFailed)
 			execCtx.Set(errorTag, err)
+			execCtx.abort()
 			return zero, execCtx, err
 		}
 	}
 
+	execCtx.publish(EventFlowStarted)
+
 	// Check for cancellation before executing the flow
 	select {
 	case <-ctx.Done():
 		execCtx.Set(endTimeTag, time.Now())
 		execCtx.Set(statusTag, ExecutionStatusCancelled)
 		execCtx.Set(errorTag, ctx.Err())
+		execCtx.abort()
 		return zero, execCtx, ctx.Err()
 	default:
 	}
 
-	result, err := executeFlow(execCtx, flow)
+	if result, skipped, err := skipExecution[R](execCtx, exts); skipped {
+		return result, execCtx, err
+	}
+
+	result, err := wrapExec(execCtx, flow, exts, func() (R, error) {
+		return executeFlow(execCtx, flow)
+	})
 
 	execCtx.Set(endTimeTag, time.Now())
 	if err != nil {
-		// Check if this is a cancellation error
-		if errors.Is(err, context.Canceled) {
-			execCtx.Set(statusTag, ExecutionStatusCancelled)
-		} else {
-			execCtx.Set(statusTag, ExecutionStatusFailed)
-		}
+		execCtx.Set(statusTag, statusForError(err))
 		execCtx.Set(errorTag, err)
 	} else {
 		execCtx.Set(statusTag, ExecutionStatusSuccess)